@@ -0,0 +1,194 @@
+package main
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"server/types"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// SetupAdminRoutes mounts the round-control API under /api/admin. Every request
+// must carry "Authorization: Bearer <token>". The API is disabled when no token
+// is configured.
+func SetupAdminRoutes(router chi.Router, token string) {
+	if token == "" {
+		log.Printf("⚠️ ADMIN_TOKEN not set, admin API disabled")
+		return
+	}
+
+	router.Route("/api/admin", func(r chi.Router) {
+		r.Use(requireAdminToken(token))
+
+		r.Post("/round/pause", adminAction("pause round", func(r *http.Request) error {
+			return natsGameManager.PauseRound()
+		}))
+		r.Post("/round/resume", adminAction("resume round", func(r *http.Request) error {
+			return natsGameManager.ResumeRound()
+		}))
+		r.Post("/round/start", adminAction("force-start round", func(r *http.Request) error {
+			return natsGameManager.ForceStartRound()
+		}))
+		r.Post("/round/end", adminAction("force-end round", func(r *http.Request) error {
+			return natsGameManager.ForceEndRound()
+		}))
+		r.Post("/round/time", adminAction("adjust round time", func(r *http.Request) error {
+			delta, err := time.ParseDuration(r.URL.Query().Get("delta"))
+			if err != nil {
+				return badRequest("delta must be a duration such as 30s or -1m")
+			}
+			return natsGameManager.AdjustRoundTime(delta)
+		}))
+		r.Post("/grid/reset", adminAction("reset grid", func(r *http.Request) error {
+			return natsGameManager.ResetGrid()
+		}))
+
+		r.Post("/players/{playerID}/kick", adminAction("kick player", func(r *http.Request) error {
+			playerID := chi.URLParam(r, "playerID")
+			if err := natsGameManager.KickPlayer(playerID); err != nil {
+				return err
+			}
+			disconnectPlayer(playerID)
+			return nil
+		}))
+		r.Post("/players/{playerID}/ban", adminAction("ban player", func(r *http.Request) error {
+			playerID := chi.URLParam(r, "playerID")
+			if err := natsGameManager.BanPlayer(playerID); err != nil {
+				return err
+			}
+			disconnectPlayer(playerID)
+			return nil
+		}))
+		r.Delete("/players/{playerID}/ban", adminAction("unban player", func(r *http.Request) error {
+			return natsGameManager.UnbanPlayer(chi.URLParam(r, "playerID"))
+		}))
+		r.Post("/players/{playerID}/bits", adminAction("grant bits", func(r *http.Request) error {
+			amount, err := strconv.Atoi(r.URL.Query().Get("amount"))
+			if err != nil || amount <= 0 {
+				return badRequest("amount must be a positive integer")
+			}
+			return natsGameManager.GrantBits(chi.URLParam(r, "playerID"), amount)
+		}))
+
+		// Agent tournaments. Agents are identified by exactly one of playerId,
+		// sessionId (a connected MCP session) or token.
+		r.Post("/tournament/agents", adminAction("register tournament agent", func(r *http.Request) error {
+			q := r.URL.Query()
+			playerID, err := mcpGameServer.agentPlayerID(q.Get("playerId"), q.Get("sessionId"), q.Get("token"))
+			if err != nil {
+				return badRequest(err.Error())
+			}
+			if team := q.Get("team"); team != "" && !slices.Contains(types.TeamIDs, team) {
+				return badRequest("unknown team " + team)
+			}
+			_, err = natsGameManager.RegisterAgent(playerID, q.Get("name"), q.Get("team"))
+			return err
+		}))
+		r.Delete("/tournament/agents/{playerID}", adminAction("remove tournament agent", func(r *http.Request) error {
+			return natsGameManager.RemoveAgent(chi.URLParam(r, "playerID"))
+		}))
+		r.Post("/tournament/start", adminAction("start tournament", func(r *http.Request) error {
+			rounds := 0
+			if v := r.URL.Query().Get("rounds"); v != "" {
+				n, err := strconv.Atoi(v)
+				if err != nil || n < 0 {
+					return badRequest("rounds must be a non-negative integer")
+				}
+				rounds = n
+			}
+			return natsGameManager.StartTournament(rounds)
+		}))
+		r.Post("/tournament/stop", adminAction("stop tournament", func(r *http.Request) error {
+			return natsGameManager.StopTournament()
+		}))
+
+		// MCP usage per player from the audit trail, busiest first
+		r.Get("/mcp/usage", func(w http.ResponseWriter, r *http.Request) {
+			window := time.Hour
+			if v := r.URL.Query().Get("since"); v != "" {
+				d, err := time.ParseDuration(v)
+				if err != nil || d <= 0 {
+					http.Error(w, "since must be a positive duration such as 15m or 24h", http.StatusBadRequest)
+					return
+				}
+				window = d
+			}
+			usage, err := natsGameManager.MCPUsage(r.Context(), time.Now().Add(-window))
+			if err != nil {
+				log.Printf("❌ Failed to summarize MCP usage: %v", err)
+				http.Error(w, "Failed to summarize MCP usage", http.StatusInternalServerError)
+				return
+			}
+			writeAPIJSON(w, usage)
+		})
+	})
+
+	log.Printf("🛡️ Admin API enabled at /api/admin")
+}
+
+// disconnectPlayer stops a removed player's bot and ends their MCP sessions.
+// Their game streams close on the next frame or chat message.
+func disconnectPlayer(playerID string) {
+	if err := botManager.Stop(playerID); err != nil && !errors.Is(err, errBotNotFound) {
+		log.Printf("⚠️ Failed to stop bot for player %s: %v", playerID, err)
+	}
+	mcpGameServer.DisconnectPlayer(playerID)
+}
+
+// requireAdminToken rejects requests whose bearer token does not match
+func requireAdminToken(token string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			provided, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !ok || subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+				log.Printf("🚫 Rejected admin request %s %s from %s", r.Method, r.URL.Path, r.RemoteAddr)
+				http.Error(w, "Unauthorized", http.StatusUnauthorized)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// badRequestError marks admin input errors that should map to 400
+type badRequestError string
+
+func (e badRequestError) Error() string { return string(e) }
+
+func badRequest(msg string) error { return badRequestError(msg) }
+
+// adminAction wraps a manager call with logging and a JSON response
+func adminAction(name string, action func(r *http.Request) error) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log.Printf("🛡️ Admin action: %s", name)
+
+		if err := action(r); err != nil {
+			log.Printf("❌ Admin action %s failed: %v", name, err)
+
+			var badReq badRequestError
+			switch {
+			case errors.As(err, &badReq):
+				http.Error(w, err.Error(), http.StatusBadRequest)
+			case errors.Is(err, types.ErrPlayerNotFound):
+				http.Error(w, err.Error(), http.StatusNotFound)
+			default:
+				http.Error(w, err.Error(), http.StatusConflict)
+			}
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"status": "ok",
+			"action": name,
+		})
+	}
+}
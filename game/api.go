@@ -0,0 +1,107 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"server/types"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// SetupAPIRoutes mounts the read-only JSON API. It serves the same shapes as
+// the MCP resources game://state, game://player/{id}, game://team/{id},
+// game://grid/{x0},{y0}-{x1},{y1} and game://rounds/{id}, plus the tournament
+// standings.
+func SetupAPIRoutes(router chi.Router) {
+	router.With(rateLimiter.Limit).Get("/api/state", func(w http.ResponseWriter, r *http.Request) {
+		gameState, err := natsGameManager.GetGameState()
+		if err != nil {
+			http.Error(w, "Failed to get game state", http.StatusInternalServerError)
+			return
+		}
+		writeAPIJSON(w, newGameStateJSON(gameState))
+	})
+
+	router.With(rateLimiter.Limit).Get("/api/players/{playerID}", func(w http.ResponseWriter, r *http.Request) {
+		player, _ := natsGameManager.GetPlayer(chi.URLParam(r, "playerID"))
+		if player == nil {
+			http.Error(w, "Player not found", http.StatusNotFound)
+			return
+		}
+		writeAPIJSON(w, newPlayerJSON(player))
+	})
+
+	router.With(rateLimiter.Limit).Get("/api/teams/{teamID}", func(w http.ResponseWriter, r *http.Request) {
+		gameState, err := natsGameManager.GetGameState()
+		if err != nil {
+			http.Error(w, "Failed to get game state", http.StatusInternalServerError)
+			return
+		}
+		team, ok := gameState.Teams[chi.URLParam(r, "teamID")]
+		if !ok {
+			http.Error(w, "Team not found", http.StatusNotFound)
+			return
+		}
+		writeAPIJSON(w, newTeamJSON(team, true))
+	})
+
+	router.With(rateLimiter.Limit).Get("/api/grid", func(w http.ResponseWriter, r *http.Request) {
+		x0, y0, x1, y1 := 0, 0, types.GridWidth-1, types.GridHeight-1
+		for name, dst := range map[string]*int{"x0": &x0, "y0": &y0, "x1": &x1, "y1": &y1} {
+			if v := r.URL.Query().Get(name); v != "" {
+				n, err := strconv.Atoi(v)
+				if err != nil {
+					http.Error(w, name+" must be an integer", http.StatusBadRequest)
+					return
+				}
+				*dst = n
+			}
+		}
+
+		gameState, err := natsGameManager.GetGameState()
+		if err != nil {
+			http.Error(w, "Failed to get game state", http.StatusInternalServerError)
+			return
+		}
+		region, err := newGridRegionJSON(gameState, x0, y0, x1, y1)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		writeAPIJSON(w, region)
+	})
+
+	router.With(rateLimiter.Limit).Get("/api/rounds/{roundID}", func(w http.ResponseWriter, r *http.Request) {
+		roundID, err := strconv.Atoi(chi.URLParam(r, "roundID"))
+		if err != nil {
+			http.Error(w, "Invalid round ID", http.StatusBadRequest)
+			return
+		}
+		record, err := natsGameManager.GetRound(roundID)
+		if errors.Is(err, types.ErrRoundNotFound) {
+			http.Error(w, "Round not found", http.StatusNotFound)
+			return
+		} else if err != nil {
+			http.Error(w, "Failed to get round", http.StatusInternalServerError)
+			return
+		}
+		writeAPIJSON(w, record)
+	})
+
+	router.With(rateLimiter.Limit).Get("/api/tournament", func(w http.ResponseWriter, r *http.Request) {
+		tournament, err := natsGameManager.GetTournament()
+		if err != nil {
+			http.Error(w, "Failed to get tournament", http.StatusInternalServerError)
+			return
+		}
+		writeAPIJSON(w, tournament)
+	})
+}
+
+func writeAPIJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,108 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidToken = errors.New("invalid session token")
+	ErrExpiredToken = errors.New("session token expired")
+)
+
+// Claims is the signed payload of a player session token
+type Claims struct {
+	PlayerID  string `json:"sub"`
+	IssuedAt  int64  `json:"iat"`
+	ExpiresAt int64  `json:"exp"`
+}
+
+// Expiry returns the token expiry as a time
+func (c *Claims) Expiry() time.Time {
+	return time.Unix(c.ExpiresAt, 0)
+}
+
+// Signer issues and verifies HMAC-SHA256 signed session tokens of the form
+// base64url(claims) "." base64url(signature)
+type Signer struct {
+	secret []byte
+	ttl    time.Duration
+}
+
+// NewSigner creates a signer with the given secret and token lifetime
+func NewSigner(secret []byte, ttl time.Duration) *Signer {
+	return &Signer{secret: secret, ttl: ttl}
+}
+
+// RandomSecret returns a 32-byte secret for use when none is configured
+func RandomSecret() []byte {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		panic(fmt.Sprintf("failed to generate session secret: %v", err))
+	}
+	return secret
+}
+
+// TTL returns the lifetime of issued tokens
+func (s *Signer) TTL() time.Duration {
+	return s.ttl
+}
+
+// Issue creates a token for playerID
+func (s *Signer) Issue(playerID string) (string, *Claims, error) {
+	now := time.Now()
+	claims := &Claims{
+		PlayerID:  playerID,
+		IssuedAt:  now.Unix(),
+		ExpiresAt: now.Add(s.ttl).Unix(),
+	}
+
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to marshal claims: %w", err)
+	}
+
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + s.sign(encoded), claims, nil
+}
+
+// Verify checks the token signature and expiry and returns its claims
+func (s *Signer) Verify(token string) (*Claims, error) {
+	encoded, signature, ok := strings.Cut(token, ".")
+	if !ok || encoded == "" || signature == "" {
+		return nil, ErrInvalidToken
+	}
+
+	if !hmac.Equal([]byte(signature), []byte(s.sign(encoded))) {
+		return nil, ErrInvalidToken
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	var claims Claims
+	if err := json.Unmarshal(payload, &claims); err != nil || claims.PlayerID == "" {
+		return nil, ErrInvalidToken
+	}
+
+	if time.Now().Unix() >= claims.ExpiresAt {
+		return nil, ErrExpiredToken
+	}
+
+	return &claims, nil
+}
+
+func (s *Signer) sign(encoded string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(encoded))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
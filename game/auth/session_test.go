@@ -0,0 +1,71 @@
+package auth
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSignerVerify(t *testing.T) {
+	signer := NewSigner([]byte("secret"), time.Hour)
+	token, _, err := signer.Issue("player-1")
+	if err != nil {
+		t.Fatalf("Issue: %v", err)
+	}
+	encoded, signature, _ := strings.Cut(token, ".")
+	flipped := "A"
+	if signature[0] == 'A' {
+		flipped = "B"
+	}
+
+	expired, _, err := NewSigner([]byte("secret"), -time.Second).Issue("player-1")
+	if err != nil {
+		t.Fatalf("Issue: %v", err)
+	}
+	forged := base64.RawURLEncoding.EncodeToString([]byte(`{"sub":"player-2","iat":0,"exp":9999999999}`))
+	noPlayer := base64.RawURLEncoding.EncodeToString([]byte(`{"iat":0,"exp":9999999999}`))
+
+	tests := []struct {
+		name    string
+		signer  *Signer
+		token   string
+		player  string
+		wantErr error
+	}{
+		{"valid", signer, token, "player-1", nil},
+		{"expired", signer, expired, "", ErrExpiredToken},
+		{"other secret", NewSigner([]byte("other"), time.Hour), token, "", ErrInvalidToken},
+		{"tampered claims", signer, forged + "." + signature, "", ErrInvalidToken},
+		{"tampered signature", signer, encoded + "." + flipped + signature[1:], "", ErrInvalidToken},
+		{"re-signed claims", signer, forged + "." + signer.sign(forged), "player-2", nil},
+		{"missing player", signer, noPlayer + "." + signer.sign(noPlayer), "", ErrInvalidToken},
+		{"bad payload", signer, "!!." + signer.sign("!!"), "", ErrInvalidToken},
+		{"no signature", signer, encoded, "", ErrInvalidToken},
+		{"empty signature", signer, encoded + ".", "", ErrInvalidToken},
+		{"empty", signer, "", "", ErrInvalidToken},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := tt.signer.Verify(tt.token)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Verify error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && claims.PlayerID != tt.player {
+				t.Errorf("PlayerID = %q, want %q", claims.PlayerID, tt.player)
+			}
+		})
+	}
+}
+
+func TestSignerIssueExpiry(t *testing.T) {
+	signer := NewSigner([]byte("secret"), 2*time.Hour)
+	_, claims, err := signer.Issue("player-1")
+	if err != nil {
+		t.Fatalf("Issue: %v", err)
+	}
+	if got := claims.ExpiresAt - claims.IssuedAt; got != int64((2 * time.Hour).Seconds()) {
+		t.Errorf("token lifetime = %ds, want %ds", got, int64((2 * time.Hour).Seconds()))
+	}
+}
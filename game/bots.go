@@ -0,0 +1,546 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"server/types"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Server-side bots act as their player and keep running without an open tab.
+// A bot is either a JavaScript script run in a goja sandbox (bots_goja.go) or a
+// WebAssembly module run in wazero (bots_wazero.go). Updates of all bots run one
+// at a time, each within a CPU time and memory limit; a bot that breaks a
+// limit MaxFaults times is stopped.
+
+// Global server-side bot runner
+var botManager *BotManager
+
+// botMaxLogLines is how many console.log lines a bot's status keeps
+const botMaxLogLines = 20
+
+var (
+	errBotCPULimit    = errors.New("bot exceeded its CPU time limit")
+	errBotMemoryLimit = errors.New("bot exceeded its memory limit")
+	errBotNotFound    = errors.New("no bot running for this player")
+	errBotLimit       = errors.New("too many bots are running")
+)
+
+// BotScriptError is a script that does not compile or fails while loading
+type BotScriptError struct {
+	Err error
+}
+
+func (e *BotScriptError) Error() string { return "bot script failed: " + e.Err.Error() }
+
+func (e *BotScriptError) Unwrap() error { return e.Err }
+
+// BotConfig limits what a single bot may use
+type BotConfig struct {
+	// Wall-clock time one update may run
+	CPULimit time.Duration
+
+	// Memory a JavaScript VM may retain; the linear memory size of a
+	// WebAssembly module
+	MemoryLimit uint64
+
+	// place() calls allowed per round
+	ActionsPerRound int
+
+	// Minimum time between update events
+	Tick time.Duration
+
+	MaxScriptBytes int
+	MaxModuleBytes int
+
+	// Limit violations before the bot is stopped
+	MaxFaults int
+
+	// Bots that may run at once. Updates share one CPU, so with N bots each
+	// waits up to N*CPULimit for its turn.
+	MaxBots int
+}
+
+// DefaultBotConfig returns limits that let a bot act on every action cooldown
+func DefaultBotConfig() *BotConfig {
+	return &BotConfig{
+		CPULimit:        50 * time.Millisecond,
+		MemoryLimit:     16 << 20,
+		ActionsPerRound: 300,
+		Tick:            types.ActionCooldown,
+		MaxScriptBytes:  64 << 10,
+		MaxModuleBytes:  512 << 10,
+		MaxFaults:       3,
+		MaxBots:         20,
+	}
+}
+
+// BotConfigFromEnv applies BOT_* overrides to the defaults
+func BotConfigFromEnv() *BotConfig {
+	config := DefaultBotConfig()
+	config.CPULimit = envDuration("BOT_CPU_LIMIT", config.CPULimit)
+	if mb := envInt("BOT_MEMORY_LIMIT_MB", 0); mb > 0 {
+		config.MemoryLimit = uint64(mb) << 20
+	}
+	config.ActionsPerRound = envInt("BOT_ACTIONS_PER_ROUND", config.ActionsPerRound)
+	config.Tick = envDuration("BOT_TICK", config.Tick)
+	config.MaxScriptBytes = envInt("BOT_MAX_SCRIPT_BYTES", config.MaxScriptBytes)
+	config.MaxModuleBytes = envInt("BOT_MAX_MODULE_BYTES", config.MaxModuleBytes)
+	config.MaxFaults = envInt("BOT_MAX_FAULTS", config.MaxFaults)
+	config.MaxBots = envInt("BOT_MAX_BOTS", config.MaxBots)
+	return config
+}
+
+// BotStatus is what a player sees about their bot
+type BotStatus struct {
+	PlayerID     string    `json:"playerId"`
+	Kind         string    `json:"kind"`
+	Running      bool      `json:"running"`
+	StartedAt    time.Time `json:"startedAt"`
+	RoundID      int       `json:"roundId"`
+	Actions      int       `json:"actions"` // place() calls this round
+	ActionBudget int       `json:"actionBudget"`
+	Placed       int       `json:"placed"` // bits placed since the bot started
+	Errors       int       `json:"errors"` // exceptions and traps
+	Faults       int       `json:"faults"` // CPU or memory limit violations
+	LastError    string    `json:"lastError,omitempty"`
+	Logs         []string  `json:"logs"`
+}
+
+// BotManager runs the server-side bots, at most one per player
+type BotManager struct {
+	ctx         context.Context
+	config      *BotConfig
+	gameManager types.NATSManager
+	hub         *BroadcastHub
+
+	// exec serializes updates so all bots together use at most one CPU
+	exec sync.Mutex
+
+	mu   sync.Mutex
+	bots map[string]*Bot
+}
+
+// NewBotManager creates the runner and restores stored bots. Bots stop when
+// ctx is done.
+func NewBotManager(ctx context.Context, gameManager types.NATSManager, hub *BroadcastHub, config *BotConfig) (*BotManager, error) {
+	if config == nil {
+		config = DefaultBotConfig()
+	}
+	m := &BotManager{
+		ctx:         ctx,
+		config:      config,
+		gameManager: gameManager,
+		hub:         hub,
+		bots:        make(map[string]*Bot),
+	}
+
+	scripts, err := gameManager.BotScripts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, script := range scripts {
+		if _, err := m.start(script); err != nil {
+			log.Printf("⚠️ Failed to restore bot for player %s: %v", script.PlayerID, err)
+		}
+	}
+
+	log.Printf("🤖 Bot runtime started with %d of at most %d bots (CPU %v, memory %d MB, %d actions per round per bot)",
+		len(m.bots), config.MaxBots, config.CPULimit, config.MemoryLimit>>20, config.ActionsPerRound)
+	return m, nil
+}
+
+// Start runs script as the player's bot, replacing a running one, and
+// stores it so it survives restarts
+func (m *BotManager) Start(script *types.BotScript) (*BotStatus, error) {
+	switch script.Kind {
+	case types.BotKindJS:
+		if len(script.Source) > m.config.MaxScriptBytes {
+			return nil, &BotScriptError{Err: fmt.Errorf("script is larger than %d bytes", m.config.MaxScriptBytes)}
+		}
+	case types.BotKindWASM:
+		if len(script.Module) > m.config.MaxModuleBytes {
+			return nil, &BotScriptError{Err: fmt.Errorf("module is larger than %d bytes", m.config.MaxModuleBytes)}
+		}
+	default:
+		return nil, &BotScriptError{Err: fmt.Errorf("unknown bot kind %q", script.Kind)}
+	}
+
+	status, err := m.start(script)
+	if err != nil {
+		return nil, err
+	}
+	script.UpdatedAt = time.Now()
+	if err := m.gameManager.SaveBotScript(script); err != nil {
+		m.Stop(script.PlayerID)
+		return nil, err
+	}
+	return status, nil
+}
+
+func (m *BotManager) start(script *types.BotScript) (*BotStatus, error) {
+	kind := script.Kind
+	if kind == "" {
+		kind = types.BotKindJS
+	}
+	if m.full(script.PlayerID) {
+		return nil, errBotLimit
+	}
+	bot := newBot(m, script.PlayerID, kind)
+
+	var err error
+	m.exec.Lock()
+	if kind == types.BotKindWASM {
+		bot.engine, err = newWASMEngine(bot, script.Module)
+	} else {
+		bot.engine, err = newJSEngine(bot, script.Source)
+	}
+	m.exec.Unlock()
+	if err != nil {
+		bot.cancel()
+		return nil, &BotScriptError{Err: err}
+	}
+
+	m.mu.Lock()
+	if m.fullLocked(script.PlayerID) {
+		m.mu.Unlock()
+		bot.cancel()
+		bot.engine.close()
+		return nil, errBotLimit
+	}
+	previous := m.bots[script.PlayerID]
+	m.bots[script.PlayerID] = bot
+	m.mu.Unlock()
+	if previous != nil {
+		previous.stop()
+	}
+
+	go bot.run()
+
+	log.Printf("🤖 Started %s bot for player %s", kind, script.PlayerID)
+	return bot.Status(), nil
+}
+
+// full reports whether starting a bot for playerID would pass MaxBots; a bot
+// replacing the player's own does not count
+func (m *BotManager) full(playerID string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.fullLocked(playerID)
+}
+
+func (m *BotManager) fullLocked(playerID string) bool {
+	if m.config.MaxBots <= 0 {
+		return false
+	}
+	running := 0
+	for id, bot := range m.bots {
+		if id != playerID && bot.Status().Running {
+			running++
+		}
+	}
+	return running >= m.config.MaxBots
+}
+
+// Stop stops and forgets the player's bot
+func (m *BotManager) Stop(playerID string) error {
+	m.mu.Lock()
+	bot, ok := m.bots[playerID]
+	delete(m.bots, playerID)
+	m.mu.Unlock()
+
+	if err := m.gameManager.DeleteBotScript(playerID); err != nil {
+		return err
+	}
+	if !ok {
+		return errBotNotFound
+	}
+	bot.stop()
+	log.Printf("🤖 Stopped bot for player %s", playerID)
+	return nil
+}
+
+// Status returns the player's bot status, including bots stopped for faults
+func (m *BotManager) Status(playerID string) (*BotStatus, bool) {
+	m.mu.Lock()
+	bot, ok := m.bots[playerID]
+	m.mu.Unlock()
+	if !ok {
+		return nil, false
+	}
+	return bot.Status(), true
+}
+
+// botEngine runs a bot's code. Its methods are only called from the bot's
+// goroutine.
+type botEngine interface {
+	// update hands the engine the latest state as JSON, with the exec lock
+	// held. It returns errBotCPULimit or errBotMemoryLimit, possibly
+	// wrapped, for limit violations.
+	update(state string) error
+	// checkMemory returns errBotMemoryLimit when the bot retains more than
+	// the memory limit. It is called after update without the exec lock.
+	checkMemory() error
+	close()
+}
+
+// Bot is one running bot
+type Bot struct {
+	manager  *BotManager
+	playerID string
+	engine   botEngine
+	ctx      context.Context
+	cancel   context.CancelFunc
+
+	mu     sync.Mutex
+	status BotStatus
+}
+
+func newBot(m *BotManager, playerID, kind string) *Bot {
+	ctx, cancel := context.WithCancel(m.ctx)
+	return &Bot{
+		manager:  m,
+		playerID: playerID,
+		ctx:      ctx,
+		cancel:   cancel,
+		status: BotStatus{
+			PlayerID:     playerID,
+			Kind:         kind,
+			Running:      true,
+			StartedAt:    time.Now(),
+			ActionBudget: m.config.ActionsPerRound,
+			Logs:         []string{},
+		},
+	}
+}
+
+// Status returns a copy of the bot's status
+func (b *Bot) Status() *BotStatus {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	status := b.status
+	status.Logs = append([]string{}, b.status.Logs...)
+	return &status
+}
+
+// run delivers state updates until the bot is stopped
+func (b *Bot) run() {
+	sub := b.manager.hub.Subscribe()
+	defer b.manager.hub.Unsubscribe(sub)
+	defer b.engine.close()
+
+	for {
+		select {
+		case <-b.ctx.Done():
+			return
+		case frame := <-sub.frames:
+			if !b.update(frame) {
+				return
+			}
+		}
+
+		// The subscriber keeps only the newest frame while we wait
+		select {
+		case <-b.ctx.Done():
+			return
+		case <-time.After(b.manager.config.Tick):
+		}
+	}
+}
+
+// update passes frame to the engine. It reports false once the bot has been
+// stopped for faults.
+func (b *Bot) update(frame *hubFrame) bool {
+	gm := b.manager.gameManager
+	player, _ := gm.GetPlayer(b.playerID)
+	if player == nil {
+		// Rejoin after a lost state; bans, kick cool-offs and tournaments keep
+		// the bot waiting
+		if _, err := gm.AddPlayer(b.playerID); err != nil {
+			return true
+		}
+		if player, _ = gm.GetPlayer(b.playerID); player == nil {
+			return true
+		}
+	}
+
+	data, err := json.Marshal(botGameState(frame, player))
+	if err != nil {
+		log.Printf("⚠️ Failed to encode bot state: %v", err)
+		return true
+	}
+
+	b.mu.Lock()
+	if b.status.RoundID != frame.state.RoundID {
+		b.status.RoundID = frame.state.RoundID
+		b.status.Actions = 0
+	}
+	b.mu.Unlock()
+
+	b.manager.exec.Lock()
+	err = b.engine.update(string(data))
+	b.manager.exec.Unlock()
+	if err == nil {
+		err = b.engine.checkMemory()
+	}
+	if err != nil {
+		return b.fail(err)
+	}
+	return true
+}
+
+// fail records an update error. Limit violations count as faults; it
+// reports false once the bot is stopped for too many of them.
+func (b *Bot) fail(err error) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !b.status.Running {
+		// Interrupted by stop
+		return false
+	}
+	b.status.LastError = err.Error()
+	if !errors.Is(err, errBotCPULimit) && !errors.Is(err, errBotMemoryLimit) {
+		b.status.Errors++
+		return true
+	}
+	b.status.Faults++
+	if b.status.Faults < b.manager.config.MaxFaults {
+		return true
+	}
+
+	b.status.Running = false
+	b.status.LastError = fmt.Sprintf("stopped after %d limit violations: %s", b.status.Faults, b.status.LastError)
+	if err := b.manager.gameManager.DeleteBotScript(b.playerID); err != nil {
+		log.Printf("⚠️ %v", err)
+	}
+	log.Printf("🛑 Stopped bot for player %s: %s", b.playerID, b.status.LastError)
+	return false
+}
+
+// stop ends the bot's goroutine and interrupts a running update
+func (b *Bot) stop() {
+	b.mu.Lock()
+	b.status.Running = false
+	b.mu.Unlock()
+	b.cancel()
+}
+
+// place places a bit for the bot's player within the action budget and
+// reports whether it landed
+func (b *Bot) place(x, y int) bool {
+	if checkRegion(x, y, x, y) != nil {
+		return false
+	}
+
+	b.mu.Lock()
+	if b.status.Actions >= b.manager.config.ActionsPerRound {
+		b.mu.Unlock()
+		return false
+	}
+	b.status.Actions++
+	b.mu.Unlock()
+
+	placed, err := b.manager.gameManager.PlaceBit(b.playerID, x, y)
+	if err != nil || !placed {
+		return false
+	}
+
+	b.mu.Lock()
+	b.status.Placed++
+	b.mu.Unlock()
+	return true
+}
+
+// log keeps a line of bot output in its status
+func (b *Bot) log(line string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.status.Logs = append(b.status.Logs, line)
+	if len(b.status.Logs) > botMaxLogLines {
+		b.status.Logs = b.status.Logs[len(b.status.Logs)-botMaxLogLines:]
+	}
+}
+
+// SetupBotRoutes mounts the player's bot API at /api/bot: PUT a script or a
+// WebAssembly module to run it, GET its status, DELETE to stop it
+func SetupBotRoutes(router chi.Router) {
+	router.Route("/api/bot", func(r chi.Router) {
+		r.Use(rateLimiter.Limit)
+
+		r.Get("/", func(w http.ResponseWriter, r *http.Request) {
+			claims, err := authenticatePlayer(r)
+			if err != nil {
+				writePlayerIDError(w, err)
+				return
+			}
+			status, ok := botManager.Status(claims.PlayerID)
+			if !ok {
+				http.Error(w, errBotNotFound.Error(), http.StatusNotFound)
+				return
+			}
+			writeAPIJSON(w, status)
+		})
+
+		r.Put("/", func(w http.ResponseWriter, r *http.Request) {
+			claims, err := authenticatePlayer(r)
+			if err != nil {
+				writePlayerIDError(w, err)
+				return
+			}
+			config := botManager.config
+			body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, int64(max(config.MaxScriptBytes, config.MaxModuleBytes))))
+			if err != nil {
+				http.Error(w, fmt.Sprintf("Scripts must be at most %d bytes and modules at most %d bytes",
+					config.MaxScriptBytes, config.MaxModuleBytes), http.StatusRequestEntityTooLarge)
+				return
+			}
+
+			script := &types.BotScript{PlayerID: claims.PlayerID, Kind: types.BotKindJS, Source: string(body)}
+			if bytes.HasPrefix(body, wasmMagic) || r.Header.Get("Content-Type") == "application/wasm" {
+				script = &types.BotScript{PlayerID: claims.PlayerID, Kind: types.BotKindWASM, Module: body}
+			}
+			status, err := botManager.Start(script)
+			var scriptErr *BotScriptError
+			if errors.As(err, &scriptErr) {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			} else if errors.Is(err, errBotLimit) {
+				http.Error(w, err.Error(), http.StatusServiceUnavailable)
+				return
+			} else if err != nil {
+				log.Printf("❌ Failed to start bot: %v", err)
+				http.Error(w, "Failed to start bot", http.StatusInternalServerError)
+				return
+			}
+			writeAPIJSON(w, status)
+		})
+
+		r.Delete("/", func(w http.ResponseWriter, r *http.Request) {
+			claims, err := authenticatePlayer(r)
+			if err != nil {
+				writePlayerIDError(w, err)
+				return
+			}
+			if err := botManager.Stop(claims.PlayerID); errors.Is(err, errBotNotFound) {
+				http.Error(w, err.Error(), http.StatusNotFound)
+				return
+			} else if err != nil {
+				log.Printf("❌ Failed to stop bot: %v", err)
+				http.Error(w, "Failed to stop bot", http.StatusInternalServerError)
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+		})
+	})
+}
@@ -0,0 +1,364 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dop251/goja"
+)
+
+// JavaScript bots have the API of the in-browser engine: on('update', fn),
+// place(x, y) and getState(). Memory is limited by what the VM retains,
+// estimated by walking its heap after callbacks. Built-ins that can allocate
+// a lot in one call reserve the memory first and interrupt the script when it
+// would pass the limit; other allocations within a callback are bounded by its
+// CPU time.
+
+// botMaxCallStack bounds recursion in bot scripts
+const botMaxCallStack = 256
+
+// jsHeapCheckInterval is how many updates pass between checks of the memory
+// a VM retains
+const jsHeapCheckInterval = 5
+
+// jsAllocationGuards replaces the built-ins that allocate in proportion to
+// their arguments with wrappers that call reserve(bytes) first. Arguments are
+// converted once and passed on converted, so valueOf and length getters
+// cannot report one size to the guard and another to the built-in.
+const jsAllocationGuards = `(function (reserve) {
+	"use strict";
+	const { apply, construct, get } = Reflect;
+	const { defineProperty, getOwnPropertyDescriptor, getPrototypeOf } = Object;
+	const NativeProxy = Proxy;
+	const { hasInstance, iterator } = Symbol;
+	const bufferLength = getOwnPropertyDescriptor(ArrayBuffer.prototype, "byteLength").get;
+	const viewLength = getOwnPropertyDescriptor(getPrototypeOf(Uint8Array.prototype), "length").get;
+	const measure = (getter, value) => {
+		try {
+			return apply(getter, value, []);
+		} catch {
+			return -1;
+		}
+	};
+
+	const guardConstructor = (name, size) => {
+		const target = globalThis[name];
+		if (typeof target !== "function") {
+			return;
+		}
+		const isInstance = (value) => value instanceof target; // goja proxies lack instanceof
+		const guarded = new NativeProxy(target, {
+			apply: (target, thisArg, args) => apply(target, thisArg, size(args)),
+			construct: (target, args, newTarget) => construct(target, size(args), newTarget),
+			get: (target, key, receiver) => key === hasInstance ? isInstance : get(target, key, receiver),
+		});
+		defineProperty(target.prototype, "constructor", { value: guarded, writable: true, configurable: true });
+		globalThis[name] = guarded;
+	};
+	const guardMethod = (object, name, size) => {
+		const original = object[name];
+		defineProperty(object, name, {
+			value: { [name](...args) {
+				if (this == null) {
+					return apply(original, this, args);
+				}
+				const string = ` + "`${this}`" + `;
+				return apply(original, string, size(string, args));
+			} }[name],
+			writable: true,
+			configurable: true,
+		});
+	};
+
+	guardConstructor("ArrayBuffer", (args) => {
+		args[0] = Number(args[0]);
+		reserve(args[0]);
+		return args;
+	});
+	guardConstructor("Array", (args) => {
+		if (args.length === 1 && typeof args[0] === "number") {
+			reserve(args[0] * 16);
+		}
+		return args;
+	});
+	for (const name of ["Int8Array", "Uint8Array", "Uint8ClampedArray", "Int16Array", "Uint16Array",
+		"Int32Array", "Uint32Array", "Float32Array", "Float64Array", "BigInt64Array", "BigUint64Array"]) {
+		const bytes = globalThis[name] && globalThis[name].BYTES_PER_ELEMENT;
+		guardConstructor(name, (args) => {
+			const source = args[0];
+			if (args.length === 0 || source === null || typeof source !== "object" && typeof source !== "function") {
+				if (args.length > 0) {
+					args[0] = Number(source);
+					reserve(args[0] * bytes);
+				}
+				return args;
+			}
+			if (measure(bufferLength, source) >= 0) {
+				return args; // a view of the buffer, nothing is copied
+			}
+			const viewed = measure(viewLength, source);
+			if (viewed >= 0) {
+				reserve(viewed * bytes);
+				return args;
+			}
+			const length = Number(source.length);
+			const iterate = source[iterator];
+			reserve(length * bytes);
+			args[0] = new NativeProxy(source, {
+				get: (target, key, receiver) =>
+					key === "length" ? length :
+					key === iterator ? iterate && (() => apply(iterate, target, [])) :
+					get(target, key, receiver),
+			});
+			return args;
+		});
+	}
+
+	guardMethod(String.prototype, "repeat", (string, args) => {
+		const count = Number(args[0]);
+		reserve(string.length * count * 2);
+		return [count];
+	});
+	for (const name of ["padStart", "padEnd"]) {
+		guardMethod(String.prototype, name, (string, args) => {
+			const length = Number(args[0]);
+			reserve(length * 2);
+			return [length, args[1]];
+		});
+	}
+})`
+
+// jsEngine runs a bot script in a goja VM
+type jsEngine struct {
+	bot       *Bot
+	vm        *goja.Runtime
+	parseJSON goja.Callable
+	listeners map[string][]goja.Callable
+	state     string // latest getState() result as JSON
+	budget    *cpuBudget
+	updates   int
+	heap      uint64 // bytes the VM retained when last measured
+	reserved  uint64 // bytes reserved by guarded built-ins since
+}
+
+// newJSEngine compiles source and runs its top level within the limits
+func newJSEngine(b *Bot, source string) (*jsEngine, error) {
+	program, err := goja.Compile("bot.js", source, false)
+	if err != nil {
+		return nil, err
+	}
+
+	e := &jsEngine{
+		bot:       b,
+		vm:        goja.New(),
+		listeners: make(map[string][]goja.Callable),
+	}
+	e.vm.SetMaxCallStackSize(botMaxCallStack)
+	e.parseJSON, _ = goja.AssertFunction(e.vm.Get("JSON").ToObject(e.vm).Get("parse"))
+
+	e.vm.Set("on", e.on)
+	e.vm.Set("place", e.place)
+	e.vm.Set("getState", e.getState)
+	console := e.vm.NewObject()
+	console.Set("log", e.log)
+	console.Set("error", e.log)
+	e.vm.Set("console", console)
+
+	guards, err := e.vm.RunString(jsAllocationGuards)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load allocation guards: %w", err)
+	}
+	install, _ := goja.AssertFunction(guards)
+	if _, err := install(goja.Undefined(), e.vm.ToValue(e.reserve)); err != nil {
+		return nil, fmt.Errorf("failed to install allocation guards: %w", err)
+	}
+
+	if err := e.guard(func() error {
+		_, err := e.vm.RunProgram(program)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	if err := e.checkHeap(); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// update emits an update event to every listener
+func (e *jsEngine) update(state string) error {
+	e.state = state
+
+	var errs []error
+	for _, fn := range e.listeners["update"] {
+		err := e.guard(func() error {
+			_, err := fn(goja.Undefined(), e.getState(goja.FunctionCall{}))
+			return err
+		})
+		if errors.Is(err, errBotCPULimit) || errors.Is(err, errBotMemoryLimit) || errors.Is(err, context.Canceled) {
+			return err
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (e *jsEngine) close() {}
+
+// guard runs fn within the CPU time limit, interrupting it when the bot
+// stops. Time spent in host calls (placing bits) is not counted. A limit
+// violation is returned as its limit error.
+func (e *jsEngine) guard(fn func() error) error {
+	e.budget = newCPUBudget(e.bot.manager.config.CPULimit, func() { e.vm.Interrupt(errBotCPULimit) })
+	stopCancel := context.AfterFunc(e.bot.ctx, func() { e.vm.Interrupt(context.Canceled) })
+
+	err := fn()
+	stopCancel()
+	e.budget.stop()
+	e.vm.ClearInterrupt()
+
+	var interrupted *goja.InterruptedError
+	if errors.As(err, &interrupted) {
+		if limitErr, ok := interrupted.Value().(error); ok {
+			return limitErr
+		}
+	}
+	return err
+}
+
+// checkMemory walks the VM's heap every jsHeapCheckInterval updates; the walk
+// takes time proportional to the heap's size
+func (e *jsEngine) checkMemory() error {
+	e.updates++
+	if e.updates%jsHeapCheckInterval != 0 {
+		return nil
+	}
+	return e.checkHeap()
+}
+
+// checkHeap fails once the memory the VM retains passes the memory limit
+func (e *jsEngine) checkHeap() error {
+	if e.measureHeap() > e.bot.manager.config.MemoryLimit {
+		return errBotMemoryLimit
+	}
+	return nil
+}
+
+// measureHeap walks the VM's heap, which accounts for every reservation so far
+func (e *jsEngine) measureHeap() uint64 {
+	e.heap = jsHeapBytes(e.vm, e.bot.manager.config.MemoryLimit)
+	e.reserved = 0
+	return e.heap
+}
+
+// reserve is called by guarded built-ins before they allocate. It interrupts
+// the script when the allocation would take the VM past its memory limit,
+// measuring the heap again first since earlier reservations may be garbage.
+func (e *jsEngine) reserve(call goja.FunctionCall) goja.Value {
+	size := call.Argument(0).ToFloat()
+	if !(size > 0) {
+		return goja.Undefined() // the built-in rejects or ignores it
+	}
+
+	limit := e.bot.manager.config.MemoryLimit
+	if size > float64(limit) || e.heap+e.reserved+uint64(size) > limit && e.measureHeap()+uint64(size) > limit {
+		e.vm.Interrupt(errBotMemoryLimit)
+		return goja.Undefined()
+	}
+	e.reserved += uint64(size)
+	return goja.Undefined()
+}
+
+// cpuBudget interrupts a callback once it has run for its limit, leaving out
+// paused intervals
+type cpuBudget struct {
+	mu        sync.Mutex
+	remaining time.Duration
+	since     time.Time
+	timer     *time.Timer
+	exceeded  func()
+}
+
+func newCPUBudget(limit time.Duration, exceeded func()) *cpuBudget {
+	b := &cpuBudget{remaining: limit, exceeded: exceeded}
+	b.resume()
+	return b
+}
+
+// pause stops the clock during a host call
+func (b *cpuBudget) pause() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.timer == nil {
+		return
+	}
+	if b.timer.Stop() {
+		b.remaining -= time.Since(b.since)
+	} else {
+		b.remaining = 0 // already interrupted
+	}
+	b.timer = nil
+}
+
+// resume restarts the clock after a host call
+func (b *cpuBudget) resume() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.timer == nil && b.remaining > 0 {
+		b.since = time.Now()
+		b.timer = time.AfterFunc(b.remaining, b.exceeded)
+	}
+}
+
+func (b *cpuBudget) stop() {
+	b.pause()
+}
+
+// on registers a callback for an event; the server emits "update"
+func (e *jsEngine) on(call goja.FunctionCall) goja.Value {
+	event := call.Argument(0).String()
+	fn, ok := goja.AssertFunction(call.Argument(1))
+	if !ok {
+		panic(e.vm.NewTypeError("on(event, callback) needs a function"))
+	}
+	e.listeners[event] = append(e.listeners[event], fn)
+	return goja.Undefined()
+}
+
+// place places a bit for the bot's player and returns whether it landed
+func (e *jsEngine) place(call goja.FunctionCall) goja.Value {
+	x := int(call.Argument(0).ToInteger())
+	y := int(call.Argument(1).ToInteger())
+	e.budget.pause()
+	defer e.budget.resume()
+	return e.vm.ToValue(e.bot.place(x, y))
+}
+
+// getState returns a fresh copy of the latest state, or null before the first
+// update
+func (e *jsEngine) getState(call goja.FunctionCall) goja.Value {
+	if e.state == "" {
+		return goja.Null()
+	}
+	state, err := e.parseJSON(goja.Undefined(), e.vm.ToValue(e.state))
+	if err != nil {
+		panic(e.vm.NewGoError(err))
+	}
+	return state
+}
+
+// log keeps console output in the bot's status
+func (e *jsEngine) log(call goja.FunctionCall) goja.Value {
+	parts := make([]string, len(call.Arguments))
+	for i, arg := range call.Arguments {
+		parts[i] = arg.String()
+	}
+	e.bot.log(strings.Join(parts, " "))
+	return goja.Undefined()
+}
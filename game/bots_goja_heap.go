@@ -0,0 +1,112 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"unsafe"
+)
+
+// jsHeapBytes estimates the memory reachable from a goja VM, stopping once it
+// passes limit. Only goja's own types are followed, so Go values the VM
+// refers to (the bot, the game) are not counted.
+func jsHeapBytes(vm any, limit uint64) uint64 {
+	seen := make(map[uintptr]struct{})
+	var size uint64
+	stack := []reflect.Value{reflect.ValueOf(vm)}
+	for len(stack) > 0 && size <= limit {
+		v := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		switch v.Kind() {
+		case reflect.Pointer:
+			if v.IsNil() || !gojaType(v.Type().Elem()) || visited(seen, v.Pointer()) {
+				continue
+			}
+			size += uint64(v.Type().Elem().Size())
+			stack = append(stack, v.Elem())
+		case reflect.Interface:
+			if v.IsNil() {
+				continue
+			}
+			elem := v.Elem()
+			if elem.Kind() != reflect.Pointer {
+				if !gojaType(elem.Type()) {
+					continue
+				}
+				size += uint64(elem.Type().Size())
+			}
+			stack = append(stack, elem)
+		case reflect.Struct:
+			for i := range v.NumField() {
+				if hasPointers(v.Type().Field(i).Type) {
+					stack = append(stack, v.Field(i))
+				}
+			}
+		case reflect.Array:
+			if hasPointers(v.Type().Elem()) {
+				for i := range v.Len() {
+					stack = append(stack, v.Index(i))
+				}
+			}
+		case reflect.Slice:
+			if v.IsNil() || visited(seen, v.Pointer()) {
+				continue
+			}
+			size += uint64(v.Cap()) * uint64(v.Type().Elem().Size())
+			if hasPointers(v.Type().Elem()) {
+				for i := range v.Len() {
+					stack = append(stack, v.Index(i))
+				}
+			}
+		case reflect.Map:
+			if v.IsNil() || visited(seen, v.Pointer()) {
+				continue
+			}
+			// Go maps keep spare slots and control words; count two slots per entry
+			size += 2 * uint64(v.Len()) * uint64(v.Type().Key().Size()+v.Type().Elem().Size()+1)
+			for iter := v.MapRange(); iter.Next(); {
+				stack = append(stack, iter.Key(), iter.Value())
+			}
+		case reflect.String:
+			s := v.String()
+			if len(s) > 0 && !visited(seen, uintptr(unsafe.Pointer(unsafe.StringData(s)))) {
+				size += uint64(len(s))
+			}
+		}
+	}
+	return size
+}
+
+func visited(seen map[uintptr]struct{}, p uintptr) bool {
+	if _, ok := seen[p]; ok {
+		return true
+	}
+	seen[p] = struct{}{}
+	return false
+}
+
+// gojaType reports whether values of t belong to the VM: goja's types and
+// unnamed containers of them
+func gojaType(t reflect.Type) bool {
+	if t.Name() == "" {
+		return true
+	}
+	return t.PkgPath() == "" || strings.HasPrefix(t.PkgPath(), "github.com/dop251/goja")
+}
+
+// hasPointers reports whether values of t can refer to other memory
+func hasPointers(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Pointer, reflect.Interface, reflect.Slice, reflect.Map, reflect.String:
+		return true
+	case reflect.Array:
+		return t.Len() > 0 && hasPointers(t.Elem())
+	case reflect.Struct:
+		for i := range t.NumField() {
+			if hasPointers(t.Field(i).Type) {
+				return true
+			}
+		}
+	}
+	return false
+}
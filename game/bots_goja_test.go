@@ -0,0 +1,132 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/dop251/goja"
+)
+
+// TestJSHeapBytes pins the heap walk to goja's internals: if an upgrade hides
+// retained values from it, bots stop being held to their memory limit.
+func TestJSHeapBytes(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		min    uint64
+	}{
+		{name: "string", script: `globalThis.kept = "x".repeat(1 << 20)`, min: 1 << 20},
+		{name: "array of objects", script: `globalThis.kept = Array.from({ length: 100000 }, (_, i) => ({ i }))`, min: 100000 * 16},
+		{name: "array buffer", script: `globalThis.kept = new Uint8Array(1 << 20)`, min: 1 << 20},
+		{name: "map", script: `globalThis.kept = new Map(Array.from({ length: 100000 }, (_, i) => [i, i]))`, min: 100000 * 16},
+		{name: "closure", script: `globalThis.kept = ((s) => () => s)("y".repeat(1 << 20))`, min: 1 << 20},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vm := goja.New()
+			base := jsHeapBytes(vm, math.MaxUint64)
+
+			if _, err := vm.RunString(tt.script); err != nil {
+				t.Fatalf("RunString: %v", err)
+			}
+			if grown := jsHeapBytes(vm, math.MaxUint64) - base; grown < tt.min {
+				t.Errorf("heap grew by %d bytes, want at least %d", grown, tt.min)
+			}
+			if size := jsHeapBytes(vm, 4096); size > 4096+(1<<21) {
+				t.Errorf("walk with a 4 KiB limit counted %d bytes, want it to stop soon after the limit", size)
+			}
+
+			if _, err := vm.RunString(`delete globalThis.kept`); err != nil {
+				t.Fatalf("RunString: %v", err)
+			}
+			if size := jsHeapBytes(vm, math.MaxUint64); size > base+tt.min/2 {
+				t.Errorf("heap is %d bytes after dropping the value, want about %d", size, base)
+			}
+		})
+	}
+}
+
+// newTestJSBot returns a bot to run scripts in, with a 4 MiB memory limit
+func newTestJSBot(t *testing.T) *Bot {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	manager := &BotManager{
+		ctx: ctx,
+		config: &BotConfig{
+			CPULimit:        50 * time.Millisecond,
+			MemoryLimit:     4 << 20,
+			ActionsPerRound: 10,
+		},
+	}
+	return newBot(manager, "bot", "js")
+}
+
+func TestJSEngineLimits(t *testing.T) {
+	tests := []struct {
+		name    string
+		script  string
+		wantErr error
+	}{
+		{name: "busy loop", script: `for (;;) {}`, wantErr: errBotCPULimit},
+		{name: "busy update", script: `on("update", () => { for (;;) {} })`, wantErr: errBotCPULimit},
+		{name: "ArrayBuffer", script: `new ArrayBuffer(64 << 20)`, wantErr: errBotMemoryLimit},
+		{name: "typed array", script: `new Float64Array(1 << 20)`, wantErr: errBotMemoryLimit},
+		{name: "typed array from array-like", script: `new Uint8Array({ length: 1 << 30 })`, wantErr: errBotMemoryLimit},
+		{
+			name:   "typed array from lying array-like",
+			script: `let n = 0; if (new Uint8Array({ get length() { return n++ ? 1 << 30 : 1 } }).length !== 1) throw new Error("length")`,
+		},
+		{name: "typed array via constructor", script: `new (new Uint8Array(1).constructor)(64 << 20)`, wantErr: errBotMemoryLimit},
+		{
+			name:   "ArrayBuffer with lying size",
+			script: `let n = 0; if (new ArrayBuffer({ valueOf() { return n++ ? 1 << 30 : 1 } }).byteLength !== 1) throw new Error("size")`,
+		},
+		{name: "repeat", script: `"xy".repeat(1 << 24)`, wantErr: errBotMemoryLimit},
+		{name: "padStart", script: `"".padStart(1 << 24)`, wantErr: errBotMemoryLimit},
+		{name: "padEnd", script: `"".padEnd(1 << 24, "z")`, wantErr: errBotMemoryLimit},
+		{name: "Array(n)", script: `Array(1 << 20).fill(0)`, wantErr: errBotMemoryLimit},
+		{name: "new Array(n)", script: `new Array(1 << 20).fill(0)`, wantErr: errBotMemoryLimit},
+		{name: "array constructor", script: `[].constructor(1 << 20).fill(0)`, wantErr: errBotMemoryLimit},
+		{
+			name:    "growth across updates",
+			script:  `const kept = []; on("update", () => { for (let i = 0; i < 5000; i++) kept.push({ i }) })`,
+			wantErr: errBotMemoryLimit,
+		},
+		{
+			name: "ordinary use",
+			script: `
+				const check = (ok, what) => { if (!ok) throw new Error(what) };
+				check("ab".repeat(3) === "ababab", "repeat");
+				check("7".padStart(3, "0") === "007" && "7".padEnd(2) === "7 ", "pad");
+				check(Array(3).length === 3 && new Array(1, 2).length === 2 && Array("3")[0] === "3", "Array");
+				check([1, 2, 3].map((x) => x * 2)[2] === 6 && [] instanceof Array, "array methods");
+				check(new Uint8Array([1, 2, 3]).length === 3 && new Uint8Array(new Set([1, 2]))[1] === 2, "typed array sources");
+				check(new Uint8Array(new ArrayBuffer(8), 4).length === 4 && new Float64Array(16).byteLength === 128, "typed arrays");
+				class Row extends Array {}
+				check(new Row(2).length === 2 && new Row(2) instanceof Row, "Array subclass");
+				on("update", () => check(getState() !== null, "state"));
+			`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := newJSEngine(newTestJSBot(t), tt.script)
+			for i := 0; err == nil && i < 10; i++ {
+				if err = e.update(`{"grid":{}}`); err == nil {
+					err = e.checkMemory()
+				}
+			}
+			if tt.wantErr == nil && err != nil {
+				t.Fatalf("err = %v, want none", err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,239 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/api"
+)
+
+// WebAssembly bots are modules built from any language that targets wasm32
+// without WASI (Rust wasm32-unknown-unknown, TinyGo -target=wasm-unknown,
+// Zig wasm32-freestanding). The host ABI:
+//
+//	memory                          exported linear memory
+//	alloc(size i32) -> i32          returns a buffer of size bytes for the host
+//	decide(ptr i32, len i32) -> i64 reads the state JSON at ptr and returns
+//	                                (ptr << 32 | len) of its actions JSON, or 0
+//
+// The state is what getState() returns to JavaScript bots. The actions are a
+// JSON array of {"x": int, "y": int} in order of preference; the first that
+// can be placed is placed, since the action cooldown allows one bit per
+// update. "_initialize" runs once when the module loads if it is exported.
+//
+// The only import is bitsplat.log(ptr i32, len i32), which adds a line to the
+// bot's status. There is no clock, randomness, file or network access. Each
+// call runs within BotConfig.CPULimit and linear memory is capped at
+// BotConfig.MemoryLimit; after a trap or a limit violation the module is
+// instantiated again.
+
+// wasmHostModule is the module name bots import host functions from
+const wasmHostModule = "bitsplat"
+
+// wasmMaxActions bounds how many actions from one decide call are tried
+const wasmMaxActions = 64
+
+// wasmMaxLogBytes bounds one bitsplat.log line
+const wasmMaxLogBytes = 1024
+
+// wasmMagic starts every WebAssembly binary
+var wasmMagic = []byte("\x00asm")
+
+// wasmEngine runs a bot module in its own wazero runtime
+type wasmEngine struct {
+	bot      *Bot
+	runtime  wazero.Runtime
+	compiled wazero.CompiledModule
+	module   api.Module
+	alloc    api.Function
+	decide   api.Function
+}
+
+// newWASMEngine compiles code, checks its imports and instantiates it
+func newWASMEngine(b *Bot, code []byte) (*wasmEngine, error) {
+	pages := uint32(b.manager.config.MemoryLimit / wasmPageSize)
+	runtime := wazero.NewRuntimeWithConfig(b.ctx, wazero.NewRuntimeConfig().
+		WithCloseOnContextDone(true).
+		WithMemoryLimitPages(max(pages, 1)))
+	e := &wasmEngine{bot: b, runtime: runtime}
+
+	_, err := runtime.NewHostModuleBuilder(wasmHostModule).
+		NewFunctionBuilder().WithFunc(e.log).Export("log").
+		Instantiate(b.ctx)
+	if err != nil {
+		e.close()
+		return nil, fmt.Errorf("failed to set up host module: %w", err)
+	}
+
+	if e.compiled, err = runtime.CompileModule(b.ctx, code); err != nil {
+		e.close()
+		return nil, fmt.Errorf("invalid module: %w", err)
+	}
+	if err := checkWASMImports(e.compiled); err != nil {
+		e.close()
+		return nil, err
+	}
+	if err := e.instantiate(); err != nil {
+		e.close()
+		return nil, err
+	}
+	return e, nil
+}
+
+// checkWASMImports allows only bitsplat.log
+func checkWASMImports(compiled wazero.CompiledModule) error {
+	for _, fn := range compiled.ImportedFunctions() {
+		module, name, _ := fn.Import()
+		if module != wasmHostModule || name != "log" {
+			return fmt.Errorf("module imports %s.%s; only %s.log is available", module, name, wasmHostModule)
+		}
+	}
+	for _, mem := range compiled.ImportedMemories() {
+		module, name, _ := mem.Import()
+		return fmt.Errorf("module imports memory %s.%s; it must define and export its own", module, name)
+	}
+	return nil
+}
+
+// instantiate starts a fresh instance of the module
+func (e *wasmEngine) instantiate() error {
+	ctx, cancel := context.WithTimeout(e.bot.ctx, e.bot.manager.config.CPULimit)
+	defer cancel()
+
+	module, err := e.runtime.InstantiateModule(ctx, e.compiled,
+		wazero.NewModuleConfig().WithName("").WithStartFunctions("_initialize"))
+	if err != nil {
+		return e.callError(ctx, nil, err)
+	}
+
+	switch {
+	case module.Memory() == nil:
+		err = errors.New("module must export its memory")
+	case !hasSignature(module.ExportedFunction("alloc"), []api.ValueType{api.ValueTypeI32}, api.ValueTypeI32):
+		err = errors.New("module must export alloc(i32) -> i32")
+	case !hasSignature(module.ExportedFunction("decide"), []api.ValueType{api.ValueTypeI32, api.ValueTypeI32}, api.ValueTypeI64):
+		err = errors.New("module must export decide(i32, i32) -> i64")
+	}
+	if err != nil {
+		module.Close(context.Background())
+		return err
+	}
+
+	e.module = module
+	e.alloc = module.ExportedFunction("alloc")
+	e.decide = module.ExportedFunction("decide")
+	return nil
+}
+
+// update calls decide with the state and places the first placeable action
+func (e *wasmEngine) update(state string) error {
+	if e.module == nil {
+		if err := e.instantiate(); err != nil {
+			return err
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(e.bot.ctx, e.bot.manager.config.CPULimit)
+	defer cancel()
+	memory := e.module.Memory()
+
+	results, err := e.alloc.Call(ctx, uint64(len(state)))
+	if err != nil {
+		return e.callError(ctx, memory, err)
+	}
+	ptr := uint32(results[0])
+	if !memory.WriteString(ptr, state) {
+		e.reset()
+		return fmt.Errorf("alloc returned %d, outside the module's memory", ptr)
+	}
+
+	results, err = e.decide.Call(ctx, uint64(ptr), uint64(len(state)))
+	if err != nil {
+		return e.callError(ctx, memory, err)
+	}
+	if results[0] == 0 {
+		return nil
+	}
+	out, ok := memory.Read(uint32(results[0]>>32), uint32(results[0]))
+	if !ok {
+		e.reset()
+		return errors.New("decide returned actions outside the module's memory")
+	}
+
+	var actions []struct {
+		X int `json:"x"`
+		Y int `json:"y"`
+	}
+	if err := json.Unmarshal(out, &actions); err != nil {
+		return fmt.Errorf("decide returned invalid actions: %w", err)
+	}
+	for i, action := range actions {
+		if i == wasmMaxActions || e.bot.place(action.X, action.Y) {
+			break
+		}
+	}
+	return nil
+}
+
+// callError turns a failed call into a limit error and drops the instance,
+// whose state may be broken
+func (e *wasmEngine) callError(ctx context.Context, memory api.Memory, err error) error {
+	full := memory != nil && uint64(memory.Size())+wasmPageSize > e.bot.manager.config.MemoryLimit
+	e.reset()
+
+	switch {
+	case e.bot.ctx.Err() != nil:
+		return context.Canceled
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		return errBotCPULimit
+	case full:
+		return fmt.Errorf("%w: %v", errBotMemoryLimit, err)
+	}
+	return err
+}
+
+// reset closes the instance; the next update instantiates the module again
+func (e *wasmEngine) reset() {
+	if e.module != nil {
+		e.module.Close(context.Background())
+		e.module = nil
+	}
+}
+
+// checkMemory has nothing to do; the runtime caps linear memory
+func (e *wasmEngine) checkMemory() error { return nil }
+
+func (e *wasmEngine) close() {
+	e.runtime.Close(context.Background())
+}
+
+// log implements bitsplat.log
+func (e *wasmEngine) log(ctx context.Context, m api.Module, ptr, length uint32) {
+	line, ok := m.Memory().Read(ptr, min(length, wasmMaxLogBytes))
+	if ok {
+		e.bot.log(string(line))
+	}
+}
+
+// hasSignature reports whether fn exists with the given params and result
+func hasSignature(fn api.Function, params []api.ValueType, result api.ValueType) bool {
+	if fn == nil {
+		return false
+	}
+	def := fn.Definition()
+	if len(def.ResultTypes()) != 1 || def.ResultTypes()[0] != result || len(def.ParamTypes()) != len(params) {
+		return false
+	}
+	for i, param := range def.ParamTypes() {
+		if param != params[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// wasmPageSize is the size of a WebAssembly memory page
+const wasmPageSize = 65536
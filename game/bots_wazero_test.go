@@ -0,0 +1,167 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/api"
+)
+
+const (
+	wasmI32 = byte(api.ValueTypeI32)
+	wasmI64 = byte(api.ValueTypeI64)
+)
+
+// wasmFunc is a function of a test module: its name, signature and, for
+// imports, the module it is imported from
+type wasmFunc struct {
+	module, name    string
+	params, results []byte
+}
+
+// wasmImportMemory marks a test module that imports its memory
+type wasmImportMemory struct{ module, name string }
+
+// buildWASM assembles a module that imports the given functions and memory
+// and exports the given functions, each returning zero, plus a memory when
+// none is imported
+func buildWASM(imports []wasmFunc, memory *wasmImportMemory, exports []wasmFunc) []byte {
+	section := func(id byte, items ...[]byte) []byte {
+		body := []byte{byte(len(items))}
+		for _, item := range items {
+			body = append(body, item...)
+		}
+		return append([]byte{id, byte(len(body))}, body...)
+	}
+	name := func(s string) []byte { return append([]byte{byte(len(s))}, s...) }
+
+	var types, importEntries, funcs, exportEntries, bodies [][]byte
+	signature := func(fn wasmFunc) byte {
+		types = append(types, append(append(append([]byte{0x60, byte(len(fn.params))}, fn.params...), byte(len(fn.results))), fn.results...))
+		return byte(len(types) - 1)
+	}
+	for _, fn := range imports {
+		importEntries = append(importEntries, append(append(append(name(fn.module), name(fn.name)...), 0x00), signature(fn)))
+	}
+	if memory != nil {
+		importEntries = append(importEntries, append(append(name(memory.module), name(memory.name)...), 0x02, 0x00, 0x01))
+	}
+	for i, fn := range exports {
+		funcs = append(funcs, []byte{signature(fn)})
+		exportEntries = append(exportEntries, append(name(fn.name), 0x00, byte(len(imports)+i)))
+		body := []byte{0x00}
+		for _, result := range fn.results {
+			body = append(body, map[byte]byte{wasmI32: 0x41, wasmI64: 0x42}[result], 0x00)
+		}
+		body = append(body, 0x0b)
+		bodies = append(bodies, append([]byte{byte(len(body))}, body...))
+	}
+
+	module := []byte("\x00asm\x01\x00\x00\x00")
+	module = append(module, section(1, types...)...)
+	if len(importEntries) > 0 {
+		module = append(module, section(2, importEntries...)...)
+	}
+	module = append(module, section(3, funcs...)...)
+	if memory == nil {
+		module = append(module, section(5, []byte{0x00, 0x01})...)
+		exportEntries = append(exportEntries, append(name("memory"), 0x02, 0x00))
+	}
+	module = append(module, section(7, exportEntries...)...)
+	return append(module, section(10, bodies...)...)
+}
+
+func TestCheckWASMImports(t *testing.T) {
+	decide := []wasmFunc{{name: "decide", params: []byte{wasmI32, wasmI32}, results: []byte{wasmI64}}}
+
+	tests := []struct {
+		name    string
+		imports []wasmFunc
+		memory  *wasmImportMemory
+		wantErr string
+	}{
+		{name: "no imports"},
+		{name: "host log", imports: []wasmFunc{{module: "bitsplat", name: "log", params: []byte{wasmI32, wasmI32}}}},
+		{
+			name:    "WASI",
+			imports: []wasmFunc{{module: "wasi_snapshot_preview1", name: "fd_write", params: []byte{wasmI32, wasmI32, wasmI32, wasmI32}, results: []byte{wasmI32}}},
+			wantErr: "imports wasi_snapshot_preview1.fd_write",
+		},
+		{
+			name:    "log from another module",
+			imports: []wasmFunc{{module: "env", name: "log", params: []byte{wasmI32, wasmI32}}},
+			wantErr: "imports env.log",
+		},
+		{
+			name:    "unknown host function",
+			imports: []wasmFunc{{module: "bitsplat", name: "place"}},
+			wantErr: "imports bitsplat.place",
+		},
+		{
+			name:    "imported memory",
+			memory:  &wasmImportMemory{module: "env", name: "memory"},
+			wantErr: "imports memory env.memory",
+		},
+	}
+	ctx := context.Background()
+	runtime := wazero.NewRuntime(ctx)
+	defer runtime.Close(ctx)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			compiled, err := runtime.CompileModule(ctx, buildWASM(tt.imports, tt.memory, decide))
+			if err != nil {
+				t.Fatalf("CompileModule: %v", err)
+			}
+			err = checkWASMImports(compiled)
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Errorf("checkWASMImports: %v", err)
+			case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+				t.Errorf("checkWASMImports error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestHasSignature(t *testing.T) {
+	ctx := context.Background()
+	runtime := wazero.NewRuntime(ctx)
+	defer runtime.Close(ctx)
+
+	module, err := runtime.Instantiate(ctx, buildWASM(nil, nil, []wasmFunc{
+		{name: "alloc", params: []byte{wasmI32}, results: []byte{wasmI32}},
+		{name: "decide", params: []byte{wasmI32, wasmI32}, results: []byte{wasmI64}},
+		{name: "decide32", params: []byte{wasmI32, wasmI32}, results: []byte{wasmI32}},
+		{name: "noresult", params: []byte{wasmI32}},
+		{name: "tworesults", params: []byte{wasmI32}, results: []byte{wasmI32, wasmI32}},
+	}))
+	if err != nil {
+		t.Fatalf("Instantiate: %v", err)
+	}
+
+	tests := []struct {
+		export string
+		params []api.ValueType
+		result api.ValueType
+		want   bool
+	}{
+		{"alloc", []api.ValueType{api.ValueTypeI32}, api.ValueTypeI32, true},
+		{"decide", []api.ValueType{api.ValueTypeI32, api.ValueTypeI32}, api.ValueTypeI64, true},
+		{"decide", []api.ValueType{api.ValueTypeI32}, api.ValueTypeI64, false},
+		{"decide", []api.ValueType{api.ValueTypeI32, api.ValueTypeI64}, api.ValueTypeI64, false},
+		{"decide32", []api.ValueType{api.ValueTypeI32, api.ValueTypeI32}, api.ValueTypeI64, false},
+		{"noresult", []api.ValueType{api.ValueTypeI32}, api.ValueTypeI32, false},
+		{"tworesults", []api.ValueType{api.ValueTypeI32}, api.ValueTypeI32, false},
+		{"missing", []api.ValueType{api.ValueTypeI32}, api.ValueTypeI32, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.export, func(t *testing.T) {
+			if got := hasSignature(module.ExportedFunction(tt.export), tt.params, tt.result); got != tt.want {
+				t.Errorf("hasSignature(%s, %v, %v) = %v, want %v", tt.export, tt.params, tt.result, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,326 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+
+	"server/i18n"
+	"server/types"
+	pages "server/ui/pages/game"
+
+	"github.com/a-h/templ"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// Global SSE broadcast hub
+var broadcastHub *BroadcastHub
+
+// hubHistorySize bounds how many versions of grid deltas are kept for
+// resuming clients
+const hubHistorySize = 256
+
+// hubFrame is one game state version, rendered once and shared by every
+// subscriber. Fragments with text are rendered once per locale, and only the
+// player HUD is rendered per connection.
+type hubFrame struct {
+	version     uint64 // KV revision of the game state
+	prevVersion uint64 // version cellPatch was diffed against
+	state       *types.GameState
+	cells       map[string]types.Cell
+
+	changed   map[string]types.Cell // cells changed since prevVersion
+	gridHTML  string
+	cellPatch string   // changed cells rendered, empty if none
+	fullGrid  bool     // too many cells changed for cellPatch
+	localized sync.Map // locale -> *localizedFragments
+
+	// Shared part of the client-side bot state
+	gridJSON  json.RawMessage
+	teamsInfo map[string]map[string]interface{}
+}
+
+// localizedFragments are the shared fragments of a frame that contain text
+type localizedFragments struct {
+	leaderboardHTML string
+	roundStatusHTML string
+}
+
+// hubSubscriber receives frames; only the newest unsent frame is kept
+type hubSubscriber struct {
+	frames chan *hubFrame
+}
+
+// hubDelta is the part of a frame kept in history for resumption
+type hubDelta struct {
+	version     uint64
+	prevVersion uint64
+	changed     map[string]types.Cell
+}
+
+// BroadcastHub watches the game state KV key once, renders the shared
+// fragments for each version and fans them out to SSE subscribers. A bounded
+// history of grid deltas lets reconnecting clients catch up without a full
+// grid.
+type BroadcastHub struct {
+	mu          sync.RWMutex
+	latest      *hubFrame
+	history     []hubDelta // oldest first, at most hubHistorySize
+	subscribers map[*hubSubscriber]struct{}
+}
+
+// NewBroadcastHub creates the hub and starts watching game state until ctx is
+// done
+func NewBroadcastHub(ctx context.Context, manager types.NATSManager) (*BroadcastHub, error) {
+	hub := &BroadcastHub{
+		subscribers: make(map[*hubSubscriber]struct{}),
+	}
+
+	watcher, err := manager.WatchGameState()
+	if err != nil {
+		return nil, err
+	}
+
+	// Seed with the current state so early subscribers get a full frame
+	if entry, err := manager.GetKV().Get(ctx, "current"); err == nil {
+		hub.publish(entry)
+	} else if !errors.Is(err, jetstream.ErrKeyNotFound) {
+		log.Printf("⚠️ Broadcast hub could not load initial state: %v", err)
+	}
+
+	go hub.run(ctx, watcher)
+
+	log.Printf("📡 Broadcast hub started")
+	return hub, nil
+}
+
+func (h *BroadcastHub) run(ctx context.Context, watcher jetstream.KeyWatcher) {
+	defer watcher.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case entry, ok := <-watcher.Updates():
+			if !ok {
+				return
+			}
+			if entry == nil {
+				continue
+			}
+			h.publish(entry)
+		}
+	}
+}
+
+// publish renders a KV entry into a frame and delivers it to all subscribers
+func (h *BroadcastHub) publish(entry jetstream.KeyValueEntry) {
+	var snapshot types.GameStateSnapshot
+	if err := json.Unmarshal(entry.Value(), &snapshot); err != nil {
+		log.Printf("❌ Failed to unmarshal game state: %v", err)
+		return
+	}
+
+	h.mu.RLock()
+	prev := h.latest
+	h.mu.RUnlock()
+
+	frame, err := renderFrame(entry.Revision(), convertSnapshotToGameState(&snapshot), prev)
+	if err != nil {
+		log.Printf("❌ Failed to render game state version %d: %v", entry.Revision(), err)
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.latest = frame
+	h.history = append(h.history, hubDelta{
+		version:     frame.version,
+		prevVersion: frame.prevVersion,
+		changed:     frame.changed,
+	})
+	if len(h.history) > hubHistorySize {
+		h.history = h.history[len(h.history)-hubHistorySize:]
+	}
+	for sub := range h.subscribers {
+		sub.offer(frame)
+	}
+}
+
+// Subscribe registers a subscriber; the latest frame, if any, is queued
+// immediately
+func (h *BroadcastHub) Subscribe() *hubSubscriber {
+	sub := &hubSubscriber{frames: make(chan *hubFrame, 1)}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.subscribers[sub] = struct{}{}
+	if h.latest != nil {
+		sub.offer(h.latest)
+	}
+	return sub
+}
+
+// Unsubscribe removes a subscriber
+func (h *BroadcastHub) Unsubscribe(sub *hubSubscriber) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.subscribers, sub)
+}
+
+// changedSince merges the grid deltas from version since up to frame. It
+// reports false when since has left the history or the chain is broken.
+func (h *BroadcastHub) changedSince(since uint64, frame *hubFrame) (map[string]types.Cell, bool) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	changed := make(map[string]types.Cell)
+	if since == frame.version {
+		return changed, true
+	}
+
+	expected := since
+	for _, delta := range h.history {
+		if delta.version <= since {
+			continue
+		}
+		if delta.version > frame.version {
+			break
+		}
+		if delta.prevVersion != expected {
+			return nil, false
+		}
+		for key, cell := range delta.changed {
+			changed[key] = cell
+		}
+		expected = delta.version
+	}
+	return changed, expected == frame.version
+}
+
+// gridUpdate returns the grid fragment that brings a client at version since
+// up to frame: the shared cell patch when it is one version behind, a patch
+// merged from history when further behind, or the full grid
+func (h *BroadcastHub) gridUpdate(since uint64, frame *hubFrame) (string, error) {
+	if since != 0 && since == frame.prevVersion {
+		if frame.fullGrid {
+			return frame.gridHTML, nil
+		}
+		return frame.cellPatch, nil
+	}
+	if since != 0 {
+		if changed, ok := h.changedSince(since, frame); ok && len(changed) <= maxCellPatches {
+			return renderCellPatch(changed)
+		}
+	}
+	return frame.gridHTML, nil
+}
+
+// Latest returns the most recent frame, or nil before the first state arrives
+func (h *BroadcastHub) Latest() *hubFrame {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.latest
+}
+
+// SubscriberCount returns the number of connected subscribers
+func (h *BroadcastHub) SubscriberCount() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.subscribers)
+}
+
+// offer queues frame, replacing an older frame the subscriber has not taken
+// yet. Callers hold the hub lock, so offers never race each other.
+func (s *hubSubscriber) offer(frame *hubFrame) {
+	select {
+	case s.frames <- frame:
+		return
+	default:
+	}
+	select {
+	case <-s.frames:
+	default:
+	}
+	s.frames <- frame
+}
+
+// renderFrame renders the shared fragments for one state version, diffing
+// the grid against prev
+func renderFrame(version uint64, gameState *types.GameState, prev *hubFrame) (*hubFrame, error) {
+	frame := &hubFrame{
+		version: version,
+		state:   gameState,
+		cells:   gridCells(gameState),
+	}
+
+	var err error
+	if frame.gridHTML, err = renderFragment(pages.GridComponent(gameState)); err != nil {
+		return nil, err
+	}
+	if _, err = frame.fragments(context.Background()); err != nil {
+		return nil, err
+	}
+
+	if prev != nil {
+		frame.prevVersion = prev.version
+		frame.changed = diffCells(prev.cells, frame.cells)
+		frame.fullGrid = len(frame.changed) > maxCellPatches
+		if !frame.fullGrid {
+			if frame.cellPatch, err = renderCellPatch(frame.changed); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	grid := gridOwners(frame.cells, 0, 0, types.GridWidth-1, types.GridHeight-1)
+	if frame.gridJSON, err = json.Marshal(grid); err != nil {
+		return nil, fmt.Errorf("failed to marshal grid: %w", err)
+	}
+
+	frame.teamsInfo = make(map[string]map[string]interface{})
+	for teamID, t := range gameState.Teams {
+		frame.teamsInfo[teamID] = map[string]interface{}{
+			"score": t.Score,
+		}
+	}
+
+	return frame, nil
+}
+
+// fragments returns the leaderboard and round status in the locale of ctx,
+// rendering them the first time that locale asks
+func (f *hubFrame) fragments(ctx context.Context) (*localizedFragments, error) {
+	localizer := i18n.FromContext(ctx)
+	if cached, ok := f.localized.Load(localizer.Lang()); ok {
+		return cached.(*localizedFragments), nil
+	}
+
+	ctx = i18n.WithLocalizer(context.Background(), localizer)
+	fragments := &localizedFragments{}
+	var err error
+	if fragments.leaderboardHTML, err = renderFragmentIn(ctx, pages.LeaderboardComponent(f.state)); err != nil {
+		return nil, err
+	}
+	if fragments.roundStatusHTML, err = renderFragmentIn(ctx, pages.RoundStatusComponent(f.state)); err != nil {
+		return nil, err
+	}
+
+	cached, _ := f.localized.LoadOrStore(localizer.Lang(), fragments)
+	return cached.(*localizedFragments), nil
+}
+
+func renderFragment(component templ.Component) (string, error) {
+	return renderFragmentIn(context.Background(), component)
+}
+
+func renderFragmentIn(ctx context.Context, component templ.Component) (string, error) {
+	var buf bytes.Buffer
+	if err := component.Render(ctx, &buf); err != nil {
+		return "", fmt.Errorf("failed to render fragment: %w", err)
+	}
+	return buf.String(), nil
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"maps"
+	"testing"
+
+	"server/types"
+)
+
+func TestChangedSince(t *testing.T) {
+	red := types.Cell{OwnerID: "p1", Color: "#ef4444"}
+	blue := types.Cell{OwnerID: "p2", Color: "#3b82f6"}
+	green := types.Cell{OwnerID: "p3", Color: "#22c55e"}
+
+	// 10 -> 11 -> 12 -> 13, then a gap: 15 was diffed against 14
+	hub := &BroadcastHub{history: []hubDelta{
+		{version: 11, prevVersion: 10, changed: map[string]types.Cell{"0:0": red}},
+		{version: 12, prevVersion: 11, changed: map[string]types.Cell{"1:0": blue}},
+		{version: 13, prevVersion: 12, changed: map[string]types.Cell{"0:0": green}},
+		{version: 15, prevVersion: 14, changed: map[string]types.Cell{"2:0": red}},
+	}}
+
+	tests := []struct {
+		name   string
+		since  uint64
+		frame  uint64
+		want   map[string]types.Cell
+		wantOK bool
+	}{
+		{"up to date", 13, 13, map[string]types.Cell{}, true},
+		{"one behind", 12, 13, map[string]types.Cell{"0:0": green}, true},
+		{"merged, later deltas win", 10, 13, map[string]types.Cell{"0:0": green, "1:0": blue}, true},
+		{"up to an older frame", 10, 12, map[string]types.Cell{"0:0": red, "1:0": blue}, true},
+		{"left the history", 9, 13, nil, false},
+		{"broken chain", 13, 15, nil, false},
+		{"frame not in history", 13, 16, nil, false},
+		{"ahead of frame", 13, 12, nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := hub.changedSince(tt.since, &hubFrame{version: tt.frame})
+			if ok != tt.wantOK {
+				t.Fatalf("changedSince(%d, %d) ok = %v, want %v", tt.since, tt.frame, ok, tt.wantOK)
+			}
+			if ok && !maps.Equal(got, tt.want) {
+				t.Errorf("changedSince(%d, %d) = %v, want %v", tt.since, tt.frame, got, tt.want)
+			}
+		})
+	}
+}
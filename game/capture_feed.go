@@ -0,0 +1,86 @@
+package main
+
+import (
+	"log"
+	"sync"
+
+	"server/types"
+
+	"github.com/nats-io/nats.go"
+)
+
+// captureFeedSize is how many recent captures the overlay feed shows
+const captureFeedSize = 5
+
+// Global feed of recent bit_placed events
+var captureFeed *CaptureFeed
+
+// CaptureFeed keeps the latest bit_placed events from NATS and notifies
+// subscribers when a new one arrives
+type CaptureFeed struct {
+	mu          sync.RWMutex
+	recent      []*types.RoundEvent // newest first
+	subscribers map[chan struct{}]struct{}
+}
+
+// NewCaptureFeed subscribes to bit_placed events on the game manager's
+// connection
+func NewCaptureFeed(manager types.NATSManager) (*CaptureFeed, error) {
+	feed := &CaptureFeed{
+		subscribers: make(map[chan struct{}]struct{}),
+	}
+
+	_, err := manager.GetNC().Subscribe("game.bit_placed", func(msg *nats.Msg) {
+		event, err := types.DecodeRoundEvent(msg.Data, 0)
+		if err != nil {
+			log.Printf("⚠️ Failed to decode capture: %v", err)
+			return
+		}
+		feed.add(event)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return feed, nil
+}
+
+func (f *CaptureFeed) add(event *types.RoundEvent) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.recent = append([]*types.RoundEvent{event}, f.recent...)
+	if len(f.recent) > captureFeedSize {
+		f.recent = f.recent[:captureFeedSize]
+	}
+
+	for ch := range f.subscribers {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Recent returns the latest captures, newest first
+func (f *CaptureFeed) Recent() []*types.RoundEvent {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return append([]*types.RoundEvent(nil), f.recent...)
+}
+
+// Subscribe returns a channel signalled after each new capture
+func (f *CaptureFeed) Subscribe() chan struct{} {
+	ch := make(chan struct{}, 1)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.subscribers[ch] = struct{}{}
+	return ch
+}
+
+// Unsubscribe stops notifications on ch
+func (f *CaptureFeed) Unsubscribe(ch chan struct{}) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.subscribers, ch)
+}
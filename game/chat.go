@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"server/types"
+	pages "server/ui/pages/game"
+
+	"github.com/go-chi/chi/v5"
+	datastar "github.com/starfederation/datastar/sdk/go"
+)
+
+// chatFilterFromEnv builds the chat profanity filter from CHAT_BLOCKED_WORDS,
+// a comma-separated word list. Without it messages pass unchanged.
+func chatFilterFromEnv() types.ChatFilter {
+	words := os.Getenv("CHAT_BLOCKED_WORDS")
+	if words == "" {
+		return nil
+	}
+	return types.NewWordListChatFilter(strings.Split(words, ","))
+}
+
+// SetupChatRoutes mounts the endpoint players post team chat messages to.
+// Messages reach teammates through their /api/sse streams.
+func SetupChatRoutes(router chi.Router) {
+	router.With(rateLimiter.Limit).Post("/api/chat", func(w http.ResponseWriter, r *http.Request) {
+		claims, err := authenticatePlayer(r)
+		if err != nil {
+			writePlayerIDError(w, err)
+			return
+		}
+
+		var text string
+		emote := r.URL.Query().Get("emote") != ""
+		if emote {
+			i, err := strconv.Atoi(r.URL.Query().Get("emote"))
+			if err != nil || i < 0 || i >= len(types.ChatEmotes) {
+				http.Error(w, "Unknown emote", http.StatusBadRequest)
+				return
+			}
+			text = types.ChatEmotes[i]
+		} else {
+			text = r.FormValue("message")
+		}
+
+		_, err = natsGameManager.SendTeamChat(claims.PlayerID, text, emote)
+
+		sse := datastar.NewSSE(w, r)
+		if err != nil {
+			log.Printf("💬 Chat message from %s rejected: %v", claims.PlayerID, err)
+			sse.MergeFragmentTempl(pages.ChatErrorComponent(err.Error()))
+			return
+		}
+
+		sse.MergeFragmentTempl(pages.ChatErrorComponent(""))
+		if !emote {
+			sse.MergeFragmentTempl(pages.ChatInputComponent(), datastar.WithMergeOuter())
+		}
+	})
+}
+
+// teamChatHistory loads recent messages for the page and SSE resyncs
+func teamChatHistory(ctx context.Context, teamID string) []*types.ChatMessage {
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	messages, err := natsGameManager.TeamChatHistory(ctx, teamID, types.ChatHistorySize)
+	if err != nil {
+		log.Printf("⚠️ Failed to load chat history for %s: %v", teamID, err)
+	}
+	return messages
+}
@@ -0,0 +1,180 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"server/types"
+)
+
+// JSON shapes shared by the HTTP API, MCP resources and the client-side game
+// state signal
+
+// errInvalidRegion is returned for grid regions outside the grid or with the
+// corners swapped
+var errInvalidRegion = errors.New("invalid grid region")
+
+// PlayerJSON is the public view of a player
+type PlayerJSON struct {
+	ID          string `json:"id"`
+	TeamID      string `json:"teamId"`
+	Color       string `json:"color"`
+	Bits        int    `json:"bits"`
+	IsConnected bool   `json:"isConnected"`
+}
+
+// TeamJSON is the public view of a team. Players is only filled in where
+// the roster is asked for and omitted when the team is empty.
+type TeamJSON struct {
+	ID            string       `json:"id"`
+	Color         string       `json:"color"`
+	Score         int          `json:"score"`
+	Percentage    float32      `json:"percentage"`
+	ActivePlayers int          `json:"activePlayers"`
+	IdlePlayers   int          `json:"idlePlayers"`
+	Players       []PlayerJSON `json:"players,omitempty"`
+}
+
+// GridRegionJSON is a rectangle of the grid, corners inclusive. Cells holds
+// the owning team ID of each cell, one row per y.
+type GridRegionJSON struct {
+	X0    int        `json:"x0"`
+	Y0    int        `json:"y0"`
+	X1    int        `json:"x1"`
+	Y1    int        `json:"y1"`
+	Cells [][]string `json:"cells"`
+}
+
+// GameStateJSON is the full public game state
+type GameStateJSON struct {
+	RoundID            int                  `json:"roundId"`
+	RoundState         types.RoundState     `json:"roundState"`
+	RoundTimeRemaining float64              `json:"roundTimeRemaining"` // seconds
+	Countdown          float64              `json:"countdown"`          // seconds
+	Paused             bool                 `json:"paused"`
+	Winner             *TeamJSON            `json:"winner"`
+	Dimensions         map[string]int       `json:"dimensions"`
+	Grid               [][]string           `json:"grid"`
+	Teams              map[string]*TeamJSON `json:"teams"`
+}
+
+// CooldownJSON tells a player when they can act next. Times are in seconds;
+// NextBitIn is -1 when no bit is coming.
+type CooldownJSON struct {
+	PlayerID     string  `json:"playerId"`
+	Bits         int     `json:"bits"`
+	MaxBits      int     `json:"maxBits"`
+	CanPlace     bool    `json:"canPlace"`
+	NextActionIn float64 `json:"nextActionIn"`
+	NextBitIn    float64 `json:"nextBitIn"`
+}
+
+func newCooldownJSON(status *types.CooldownStatus) *CooldownJSON {
+	c := &CooldownJSON{
+		PlayerID:     status.PlayerID,
+		Bits:         status.Bits,
+		MaxBits:      status.MaxBits,
+		CanPlace:     status.CanPlace,
+		NextActionIn: status.NextActionIn.Seconds(),
+		NextBitIn:    -1,
+	}
+	if status.NextBitIn >= 0 {
+		c.NextBitIn = status.NextBitIn.Seconds()
+	}
+	return c
+}
+
+func newPlayerJSON(player *types.Player) PlayerJSON {
+	return PlayerJSON{
+		ID:          player.ID,
+		TeamID:      player.TeamID,
+		Color:       player.Color,
+		Bits:        player.Bits,
+		IsConnected: player.IsConnected,
+	}
+}
+
+func newTeamJSON(team *types.Team, withPlayers bool) *TeamJSON {
+	t := &TeamJSON{
+		ID:            team.ID,
+		Color:         team.Color,
+		Score:         team.Score,
+		Percentage:    team.Percentage,
+		ActivePlayers: team.ActivePlayers,
+		IdlePlayers:   team.IdlePlayers,
+	}
+	if withPlayers && team.Players != nil {
+		t.Players = make([]PlayerJSON, 0)
+		team.Players.Range(func(key, value interface{}) bool {
+			t.Players = append(t.Players, newPlayerJSON(value.(*types.Player)))
+			return true
+		})
+		sort.Slice(t.Players, func(i, j int) bool {
+			return t.Players[i].ID < t.Players[j].ID
+		})
+	}
+	return t
+}
+
+// gridOwners returns the owner of every cell in the region, one row per y.
+// cells is a full grid as built by gridCells.
+func gridOwners(cells map[string]types.Cell, x0, y0, x1, y1 int) [][]string {
+	rows := make([][]string, 0, y1-y0+1)
+	for y := y0; y <= y1; y++ {
+		row := make([]string, 0, x1-x0+1)
+		for x := x0; x <= x1; x++ {
+			owner := cells[fmt.Sprintf("%d:%d", x, y)].OwnerID
+			if owner == "" {
+				owner = types.NeutralOwnerID
+			}
+			row = append(row, owner)
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// checkRegion returns errInvalidRegion unless the rectangle lies within the grid
+func checkRegion(x0, y0, x1, y1 int) error {
+	if x0 < 0 || y0 < 0 || x1 >= types.GridWidth || y1 >= types.GridHeight || x0 > x1 || y0 > y1 {
+		return fmt.Errorf("%w: (%d,%d)-(%d,%d) must lie within 0,0-%d,%d", errInvalidRegion, x0, y0, x1, y1, types.GridWidth-1, types.GridHeight-1)
+	}
+	return nil
+}
+
+func newGridRegionJSON(gameState *types.GameState, x0, y0, x1, y1 int) (*GridRegionJSON, error) {
+	if err := checkRegion(x0, y0, x1, y1); err != nil {
+		return nil, err
+	}
+	return &GridRegionJSON{
+		X0:    x0,
+		Y0:    y0,
+		X1:    x1,
+		Y1:    y1,
+		Cells: gridOwners(gridCells(gameState), x0, y0, x1, y1),
+	}, nil
+}
+
+func newGameStateJSON(gameState *types.GameState) *GameStateJSON {
+	state := &GameStateJSON{
+		RoundID:            gameState.RoundID,
+		RoundState:         gameState.RoundState,
+		RoundTimeRemaining: gameState.RoundTimeRemaining.Seconds(),
+		Countdown:          gameState.Countdown.Seconds(),
+		Paused:             gameState.Paused,
+		Dimensions: map[string]int{
+			"width":  types.GridWidth,
+			"height": types.GridHeight,
+		},
+		Grid:  gridOwners(gridCells(gameState), 0, 0, types.GridWidth-1, types.GridHeight-1),
+		Teams: make(map[string]*TeamJSON, len(gameState.Teams)),
+	}
+	for id, team := range gameState.Teams {
+		state.Teams[id] = newTeamJSON(team, true)
+	}
+	if gameState.Winner != nil {
+		state.Winner = newTeamJSON(gameState.Winner, false)
+	}
+	return state
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"server/types"
+	pages "server/ui/pages/game"
+)
+
+// maxCellPatches is the number of changed cells above which subscribers are
+// sent the whole grid instead of per-cell fragments
+const maxCellPatches = 200
+
+// diffCells returns the cells in current that differ from prev
+func diffCells(prev, current map[string]types.Cell) map[string]types.Cell {
+	changed := make(map[string]types.Cell)
+	for key, cell := range current {
+		if prev[key] != cell {
+			changed[key] = cell
+		}
+	}
+	return changed
+}
+
+// renderCellPatch renders changed cells as one fragment string; Datastar
+// morphs each top-level element by its id
+func renderCellPatch(changed map[string]types.Cell) (string, error) {
+	var fragments strings.Builder
+	for key, cell := range changed {
+		var x, y int
+		fmt.Sscanf(key, "%d:%d", &x, &y)
+		if err := pages.CellComponent(x, y, cell).Render(context.Background(), &fragments); err != nil {
+			return "", fmt.Errorf("failed to render cell %s: %w", key, err)
+		}
+	}
+	return fragments.String(), nil
+}
+
+// gridCells flattens the grid into a map covering every position, with
+// unowned positions filled in as neutral
+func gridCells(gameState *types.GameState) map[string]types.Cell {
+	cells := make(map[string]types.Cell, types.GridWidth*types.GridHeight)
+	for y := 0; y < types.GridHeight; y++ {
+		for x := 0; x < types.GridWidth; x++ {
+			key := fmt.Sprintf("%d:%d", x, y)
+			if cell, ok := gameState.Grid.Load(key); ok {
+				cells[key] = cell.(types.Cell)
+			} else {
+				cells[key] = types.NeutralCell
+			}
+		}
+	}
+	return cells
+}
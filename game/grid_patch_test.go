@@ -0,0 +1,65 @@
+package main
+
+import (
+	"maps"
+	"testing"
+
+	"server/types"
+)
+
+func TestDiffCells(t *testing.T) {
+	red := types.Cell{OwnerID: "p1", Color: "#ef4444"}
+	blue := types.Cell{OwnerID: "p2", Color: "#3b82f6"}
+	neutral := types.NeutralCell
+
+	tests := []struct {
+		name    string
+		prev    map[string]types.Cell
+		current map[string]types.Cell
+		want    map[string]types.Cell
+	}{
+		{
+			name:    "unchanged",
+			prev:    map[string]types.Cell{"0:0": red, "1:0": neutral},
+			current: map[string]types.Cell{"0:0": red, "1:0": neutral},
+			want:    map[string]types.Cell{},
+		},
+		{
+			name:    "captured",
+			prev:    map[string]types.Cell{"0:0": red, "1:0": neutral},
+			current: map[string]types.Cell{"0:0": blue, "1:0": neutral},
+			want:    map[string]types.Cell{"0:0": blue},
+		},
+		{
+			name:    "same owner, new color",
+			prev:    map[string]types.Cell{"0:0": red},
+			current: map[string]types.Cell{"0:0": {OwnerID: "p1", Color: "#000000"}},
+			want:    map[string]types.Cell{"0:0": {OwnerID: "p1", Color: "#000000"}},
+		},
+		{
+			name:    "reset to neutral",
+			prev:    map[string]types.Cell{"0:0": red, "1:0": blue},
+			current: map[string]types.Cell{"0:0": neutral, "1:0": neutral},
+			want:    map[string]types.Cell{"0:0": neutral, "1:0": neutral},
+		},
+		{
+			name:    "new positions",
+			prev:    map[string]types.Cell{"0:0": red},
+			current: map[string]types.Cell{"0:0": red, "1:0": neutral},
+			want:    map[string]types.Cell{"1:0": neutral},
+		},
+		{
+			name:    "no previous grid",
+			prev:    nil,
+			current: map[string]types.Cell{"0:0": red},
+			want:    map[string]types.Cell{"0:0": red},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := diffCells(tt.prev, tt.current); !maps.Equal(got, tt.want) {
+				t.Errorf("diffCells = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"server/types"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// healthProbeTimeout bounds the JetStream and KV checks of a single probe
+const healthProbeTimeout = 2 * time.Second
+
+// sseSubscribers counts open /api/sse streams
+var sseSubscribers atomic.Int64
+
+// healthResponse is the JSON body of /healthz and /readyz
+type healthResponse struct {
+	Status         string              `json:"status"`
+	Failures       []string            `json:"failures,omitempty"`
+	SSESubscribers int64               `json:"sseSubscribers"`
+	Spectators     int64               `json:"spectators"`
+	Checks         *types.HealthReport `json:"checks"`
+}
+
+// SetupHealthRoutes mounts the probes used by the orchestrator.
+//
+// /healthz is liveness: it fails when the embedded NATS server is down or the
+// game loop has stalled, meaning the process should be restarted. It only
+// checks the process, so a slow or full KV bucket does not restart it.
+// /readyz is readiness: it additionally fails when JetStream or the KV bucket
+// is unreachable or the server is draining.
+func SetupHealthRoutes(router chi.Router) {
+	router.Get("/healthz", healthHandler(func(ctx context.Context) *types.HealthReport {
+		return natsGameManager.Health()
+	}, func(report *types.HealthReport) []string {
+		var failures []string
+		if !report.NATSServerRunning {
+			failures = append(failures, "embedded NATS server is not running")
+		}
+		if report.GameLoopStalled {
+			failures = append(failures, stalledLoopFailure(report))
+		}
+		return failures
+	}))
+
+	router.Get("/readyz", healthHandler(natsGameManager.Readiness, func(report *types.HealthReport) []string {
+		var failures []string
+		if !report.NATSServerRunning {
+			failures = append(failures, "embedded NATS server is not running")
+		}
+		if !report.NATSConnected {
+			failures = append(failures, "NATS connection is "+report.NATSStatus)
+		}
+		if !report.Stores.JetStreamOK {
+			failures = append(failures, "JetStream unavailable: "+report.Stores.JetStreamError)
+		}
+		if !report.Stores.KVOK {
+			failures = append(failures, "KV unreachable: "+report.Stores.KVError)
+		}
+		if report.GameLoopStalled {
+			failures = append(failures, stalledLoopFailure(report))
+		}
+		if report.Draining {
+			failures = append(failures, "server is draining")
+		}
+		return failures
+	}))
+}
+
+// healthHandler probes the game manager and responds 200 when check reports
+// no failures, 503 otherwise
+func healthHandler(probe func(ctx context.Context) *types.HealthReport, check func(report *types.HealthReport) []string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), healthProbeTimeout)
+		defer cancel()
+
+		report := probe(ctx)
+		response := healthResponse{
+			Status:         "ok",
+			Failures:       check(report),
+			SSESubscribers: sseSubscribers.Load(),
+			Spectators:     spectators.Load(),
+			Checks:         report,
+		}
+
+		status := http.StatusOK
+		if len(response.Failures) > 0 {
+			response.Status = "unavailable"
+			status = http.StatusServiceUnavailable
+			log.Printf("🩺 %s failing: %v", r.URL.Path, response.Failures)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(response)
+	}
+}
+
+func stalledLoopFailure(report *types.HealthReport) string {
+	if report.LastTick.IsZero() {
+		return "game loop has not ticked yet"
+	}
+	return "game loop stalled: last tick " + report.SinceLastTick.Round(time.Millisecond).String() + " ago"
+}
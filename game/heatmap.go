@@ -0,0 +1,224 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"server/types"
+	pages "server/ui/pages/game"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/nats-io/nats.go/jetstream"
+	datastar "github.com/starfederation/datastar/sdk/go"
+)
+
+// Heatmap modes
+const (
+	HeatmapModeChanges = "changes" // how often a cell changed hands
+	HeatmapModeRecent  = "recent"  // how recently a cell was captured
+)
+
+// heatmapRecentWindow is how long a capture keeps heat in recent mode
+const heatmapRecentWindow = 60 * time.Second
+
+// Global contested-cell heatmap for the current round
+var contestHeatmap *Heatmap
+
+// HeatmapCell is the capture history of one cell in the current round
+type HeatmapCell struct {
+	X            int       `json:"x"`
+	Y            int       `json:"y"`
+	Changes      int       `json:"changes"`  // captures from another team
+	Captures     int       `json:"captures"` // all captures, including neutral cells
+	LastCaptured time.Time `json:"lastCaptured"`
+	Intensity    float64   `json:"intensity"` // 0-1 for the requested mode
+}
+
+// HeatmapSnapshot is the heatmap served by /api/heatmap and the MCP tool
+type HeatmapSnapshot struct {
+	RoundID     int           `json:"roundId"`
+	Mode        string        `json:"mode"`
+	Width       int           `json:"width"`
+	Height      int           `json:"height"`
+	MaxChanges  int           `json:"maxChanges"`
+	GeneratedAt time.Time     `json:"generatedAt"`
+	Cells       []HeatmapCell `json:"cells"` // only cells captured this round, hottest first
+}
+
+// Heatmap follows bit_placed events on GAME_EVENTS and counts how often each
+// cell changes hands. It resets when a round starts or the grid is reset.
+type Heatmap struct {
+	mu         sync.RWMutex
+	roundID    int
+	cells      map[string]*HeatmapCell
+	maxChanges int
+}
+
+// NewHeatmap replays the current round from JetStream and keeps following it
+func NewHeatmap(ctx context.Context, manager types.NATSManager) (*Heatmap, error) {
+	h := &Heatmap{cells: make(map[string]*HeatmapCell)}
+
+	config := jetstream.OrderedConsumerConfig{
+		FilterSubjects: []string{"game.bit_placed", "game.admin.reset_grid", "game.round_started"},
+		DeliverPolicy:  jetstream.DeliverNewPolicy,
+	}
+	if gameState, err := manager.GetGameState(); err == nil && gameState.RoundID > 0 {
+		if record, err := manager.GetRound(gameState.RoundID); err == nil && record.StartSeq > 0 {
+			h.roundID = record.ID
+			config.DeliverPolicy = jetstream.DeliverByStartSequencePolicy
+			config.OptStartSeq = record.StartSeq
+		}
+	}
+
+	consumer, err := manager.GetJS().OrderedConsumer(ctx, types.StreamGameEvents, config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create heatmap consumer: %w", err)
+	}
+
+	_, err = consumer.Consume(func(msg jetstream.Msg) {
+		event, err := types.DecodeRoundEvent(msg.Data(), 0)
+		if err != nil {
+			log.Printf("⚠️ Failed to decode heatmap event: %v", err)
+			return
+		}
+		h.apply(event)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to consume heatmap events: %w", err)
+	}
+
+	return h, nil
+}
+
+func (h *Heatmap) apply(event *types.RoundEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	switch event.Kind {
+	case types.RoundEventRoundStarted:
+		h.roundID = event.RoundID
+		h.reset()
+	case types.RoundEventGridReset:
+		h.reset()
+	case types.RoundEventBitPlaced:
+		if event.RoundID != 0 && event.RoundID != h.roundID {
+			h.roundID = event.RoundID
+			h.reset()
+		}
+
+		key := fmt.Sprintf("%d:%d", event.X, event.Y)
+		cell, ok := h.cells[key]
+		if !ok {
+			cell = &HeatmapCell{X: event.X, Y: event.Y}
+			h.cells[key] = cell
+		}
+		cell.Captures++
+		cell.LastCaptured = event.At
+		if event.OldOwner != "" && event.OldOwner != types.NeutralOwnerID && event.OldOwner != event.TeamID {
+			cell.Changes++
+			h.maxChanges = max(h.maxChanges, cell.Changes)
+		}
+	}
+}
+
+// reset clears all cells. Must be called with mu held.
+func (h *Heatmap) reset() {
+	h.cells = make(map[string]*HeatmapCell)
+	h.maxChanges = 0
+}
+
+// Snapshot returns the captured cells with intensities for mode
+func (h *Heatmap) Snapshot(mode string) *HeatmapSnapshot {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	now := time.Now()
+	snapshot := &HeatmapSnapshot{
+		RoundID:     h.roundID,
+		Mode:        mode,
+		Width:       types.GridWidth,
+		Height:      types.GridHeight,
+		MaxChanges:  h.maxChanges,
+		GeneratedAt: now,
+		Cells:       make([]HeatmapCell, 0, len(h.cells)),
+	}
+
+	for _, cell := range h.cells {
+		c := *cell
+		switch mode {
+		case HeatmapModeRecent:
+			if age := now.Sub(c.LastCaptured); age < heatmapRecentWindow {
+				c.Intensity = 1 - float64(age)/float64(heatmapRecentWindow)
+			}
+		default:
+			if h.maxChanges > 0 {
+				c.Intensity = float64(c.Changes) / float64(h.maxChanges)
+			}
+		}
+		snapshot.Cells = append(snapshot.Cells, c)
+	}
+
+	sort.Slice(snapshot.Cells, func(i, j int) bool {
+		a, b := snapshot.Cells[i], snapshot.Cells[j]
+		if a.Intensity != b.Intensity {
+			return a.Intensity > b.Intensity
+		}
+		if a.Y != b.Y {
+			return a.Y < b.Y
+		}
+		return a.X < b.X
+	})
+
+	return snapshot
+}
+
+// heatmapMode reads ?mode=, defaulting to hand changes
+func heatmapMode(r *http.Request) (string, bool) {
+	switch mode := r.URL.Query().Get("mode"); mode {
+	case "":
+		return HeatmapModeChanges, true
+	case HeatmapModeChanges, HeatmapModeRecent:
+		return mode, true
+	default:
+		return "", false
+	}
+}
+
+// SetupHeatmapRoutes mounts the heatmap JSON API and the fragment the grid
+// toggle polls
+func SetupHeatmapRoutes(router chi.Router) {
+	router.With(rateLimiter.Limit).Get("/api/heatmap", func(w http.ResponseWriter, r *http.Request) {
+		mode, ok := heatmapMode(r)
+		if !ok {
+			http.Error(w, "mode must be changes or recent", http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(contestHeatmap.Snapshot(mode))
+	})
+
+	router.With(rateLimiter.Limit).Get("/api/heatmap/layer", func(w http.ResponseWriter, r *http.Request) {
+		mode, ok := heatmapMode(r)
+		if !ok {
+			http.Error(w, "mode must be changes or recent", http.StatusBadRequest)
+			return
+		}
+
+		snapshot := contestHeatmap.Snapshot(mode)
+		cells := make([]pages.HeatmapCellView, 0, len(snapshot.Cells))
+		for _, c := range snapshot.Cells {
+			if c.Intensity > 0 {
+				cells = append(cells, pages.HeatmapCellView{X: c.X, Y: c.Y, Intensity: c.Intensity})
+			}
+		}
+
+		sse := datastar.NewSSE(w, r)
+		sse.MergeFragmentTempl(pages.HeatmapLayerComponent(cells))
+	})
+}
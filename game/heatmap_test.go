@@ -0,0 +1,132 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"server/types"
+)
+
+func TestHeatmapApply(t *testing.T) {
+	at := time.Now()
+	placed := func(round, x int, team, oldOwner string) *types.RoundEvent {
+		return &types.RoundEvent{Kind: types.RoundEventBitPlaced, RoundID: round, X: x, TeamID: team, OldOwner: oldOwner, At: at}
+	}
+
+	tests := []struct {
+		name       string
+		events     []*types.RoundEvent
+		roundID    int
+		cells      map[string][2]int // key -> changes, captures
+		maxChanges int
+	}{
+		{
+			name:    "neutral capture is not a change",
+			events:  []*types.RoundEvent{placed(1, 0, "red", "neutral"), placed(1, 1, "red", "")},
+			roundID: 1,
+			cells:   map[string][2]int{"0:0": {0, 1}, "1:0": {0, 1}},
+		},
+		{
+			name:       "changes hands",
+			events:     []*types.RoundEvent{placed(1, 0, "red", "neutral"), placed(1, 0, "blue", "red"), placed(1, 0, "red", "blue")},
+			roundID:    1,
+			cells:      map[string][2]int{"0:0": {2, 3}},
+			maxChanges: 2,
+		},
+		{
+			name:    "recapture by the owning team is not a change",
+			events:  []*types.RoundEvent{placed(1, 0, "red", "neutral"), placed(1, 0, "red", "red")},
+			roundID: 1,
+			cells:   map[string][2]int{"0:0": {0, 2}},
+		},
+		{
+			name: "round start resets",
+			events: []*types.RoundEvent{
+				placed(1, 0, "blue", "red"),
+				{Kind: types.RoundEventRoundStarted, RoundID: 2},
+				placed(2, 1, "red", "neutral"),
+			},
+			roundID: 2,
+			cells:   map[string][2]int{"1:0": {0, 1}},
+		},
+		{
+			name:       "placement from a new round resets",
+			events:     []*types.RoundEvent{placed(1, 0, "blue", "red"), placed(3, 1, "blue", "red")},
+			roundID:    3,
+			cells:      map[string][2]int{"1:0": {1, 1}},
+			maxChanges: 1,
+		},
+		{
+			name:    "grid reset keeps the round",
+			events:  []*types.RoundEvent{placed(1, 0, "blue", "red"), {Kind: types.RoundEventGridReset}},
+			roundID: 1,
+			cells:   map[string][2]int{},
+		},
+		{
+			name:       "placement without a round joins the current one",
+			events:     []*types.RoundEvent{placed(1, 0, "red", "neutral"), placed(0, 0, "blue", "red")},
+			roundID:    1,
+			cells:      map[string][2]int{"0:0": {1, 2}},
+			maxChanges: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &Heatmap{cells: make(map[string]*HeatmapCell)}
+			for _, event := range tt.events {
+				h.apply(event)
+			}
+			if h.roundID != tt.roundID {
+				t.Errorf("roundID = %d, want %d", h.roundID, tt.roundID)
+			}
+			if h.maxChanges != tt.maxChanges {
+				t.Errorf("maxChanges = %d, want %d", h.maxChanges, tt.maxChanges)
+			}
+			if len(h.cells) != len(tt.cells) {
+				t.Fatalf("%d cells, want %d", len(h.cells), len(tt.cells))
+			}
+			for key, want := range tt.cells {
+				cell, ok := h.cells[key]
+				if !ok {
+					t.Fatalf("cell %s missing", key)
+				}
+				if got := [2]int{cell.Changes, cell.Captures}; got != want {
+					t.Errorf("cell %s changes, captures = %v, want %v", key, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestHeatmapSnapshot(t *testing.T) {
+	now := time.Now()
+	h := &Heatmap{cells: map[string]*HeatmapCell{
+		"0:0": {X: 0, Y: 0, Changes: 1, LastCaptured: now.Add(-2 * heatmapRecentWindow)},
+		"1:0": {X: 1, Y: 0, Changes: 4, LastCaptured: now.Add(-heatmapRecentWindow / 2)},
+		"0:1": {X: 0, Y: 1, Changes: 0, LastCaptured: now},
+	}, maxChanges: 4}
+
+	tests := []struct {
+		mode  string
+		order []int // X+10*Y of the cells, hottest first
+	}{
+		{HeatmapModeChanges, []int{1, 0, 10}},
+		{HeatmapModeRecent, []int{10, 1, 0}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			snapshot := h.Snapshot(tt.mode)
+			if len(snapshot.Cells) != len(tt.order) {
+				t.Fatalf("%d cells, want %d", len(snapshot.Cells), len(tt.order))
+			}
+			for i, cell := range snapshot.Cells {
+				if got := cell.X + 10*cell.Y; got != tt.order[i] {
+					t.Errorf("cell %d is %d:%d, want order %v", i, cell.X, cell.Y, tt.order)
+				}
+				if cell.Intensity < 0 || cell.Intensity > 1 {
+					t.Errorf("cell %d:%d intensity %v out of range", cell.X, cell.Y, cell.Intensity)
+				}
+			}
+		})
+	}
+}
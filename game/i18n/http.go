@@ -0,0 +1,45 @@
+package i18n
+
+import (
+	"net/http"
+)
+
+// LangCookie remembers a locale chosen with ?lang= so later requests from
+// the page, such as SSE streams, use it too
+const LangCookie = "lang"
+
+// Negotiate picks the locale for a request: the lang query parameter, then
+// the lang cookie, then Accept-Language
+func Negotiate(r *http.Request) *Localizer {
+	var preferences []string
+	if lang := r.URL.Query().Get("lang"); lang != "" {
+		preferences = append(preferences, lang)
+	}
+	if cookie, err := r.Cookie(LangCookie); err == nil && cookie.Value != "" {
+		preferences = append(preferences, cookie.Value)
+	}
+	if accept := r.Header.Get("Accept-Language"); accept != "" {
+		preferences = append(preferences, accept)
+	}
+	return NewLocalizer(preferences...)
+}
+
+// Middleware stores the negotiated localizer in the request context and
+// persists an explicit ?lang= choice in a cookie
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		localizer := Negotiate(r)
+		if r.URL.Query().Get("lang") != "" {
+			http.SetCookie(w, &http.Cookie{
+				Name:     LangCookie,
+				Value:    localizer.Lang(),
+				Path:     "/",
+				MaxAge:   365 * 24 * 60 * 60,
+				HttpOnly: true,
+				Secure:   r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https",
+				SameSite: http.SameSiteLaxMode,
+			})
+		}
+		next.ServeHTTP(w, r.WithContext(WithLocalizer(r.Context(), localizer)))
+	})
+}
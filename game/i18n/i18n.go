@@ -0,0 +1,208 @@
+package i18n
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"log"
+	"path"
+	"sort"
+	"strings"
+
+	"golang.org/x/text/feature/plural"
+	"golang.org/x/text/language"
+)
+
+//go:embed locales
+var localesFS embed.FS
+
+// DefaultLanguage is used when negotiation finds no better match and for
+// keys missing from a translation
+var DefaultLanguage = language.English
+
+// message is a catalog entry: a single format string, or one format string
+// per CLDR plural category ("zero", "one", "two", "few", "many", "other")
+type message struct {
+	text   string
+	plural map[string]string
+}
+
+func (m *message) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, &m.text); err == nil {
+		return nil
+	}
+	return json.Unmarshal(data, &m.plural)
+}
+
+// Catalog holds the messages of every embedded locale
+type Catalog struct {
+	messages map[language.Tag]map[string]message
+	tags     []language.Tag // DefaultLanguage first, as the matcher requires
+	matcher  language.Matcher
+}
+
+// defaultCatalog is loaded from the embedded locales directory
+var defaultCatalog = mustLoadCatalog(localesFS)
+
+// mustLoadCatalog reads every locales/<tag>.json file in fsys
+func mustLoadCatalog(fsys fs.FS) *Catalog {
+	files, err := fs.Glob(fsys, "locales/*.json")
+	if err != nil {
+		log.Fatalf("❌ Failed to list locales: %v", err)
+	}
+
+	c := &Catalog{messages: make(map[language.Tag]map[string]message)}
+	for _, file := range files {
+		tag, err := language.Parse(strings.TrimSuffix(path.Base(file), ".json"))
+		if err != nil {
+			log.Fatalf("❌ Invalid locale file name %s: %v", file, err)
+		}
+
+		data, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			log.Fatalf("❌ Failed to read %s: %v", file, err)
+		}
+		messages := make(map[string]message)
+		if err := json.Unmarshal(data, &messages); err != nil {
+			log.Fatalf("❌ Failed to parse %s: %v", file, err)
+		}
+		c.messages[tag] = messages
+	}
+	if _, ok := c.messages[DefaultLanguage]; !ok {
+		log.Fatalf("❌ Missing catalog for default language %s", DefaultLanguage)
+	}
+
+	c.tags = append(c.tags, DefaultLanguage)
+	for tag := range c.messages {
+		if tag != DefaultLanguage {
+			c.tags = append(c.tags, tag)
+		}
+	}
+	sort.Slice(c.tags[1:], func(i, j int) bool {
+		return c.tags[i+1].String() < c.tags[j+1].String()
+	})
+	c.matcher = language.NewMatcher(c.tags)
+
+	return c
+}
+
+// Languages returns the embedded locales, default first
+func Languages() []language.Tag {
+	return append([]language.Tag(nil), defaultCatalog.tags...)
+}
+
+// Localizer looks up messages for one negotiated locale, falling back to
+// DefaultLanguage and finally to the key itself
+type Localizer struct {
+	tag      language.Tag
+	messages map[string]message
+	fallback map[string]message
+}
+
+// NewLocalizer picks the best supported locale for the given preferences,
+// e.g. query parameters or Accept-Language header values
+func NewLocalizer(preferences ...string) *Localizer {
+	c := defaultCatalog
+	_, index := language.MatchStrings(c.matcher, preferences...)
+	tag := c.tags[index]
+	return &Localizer{
+		tag:      tag,
+		messages: c.messages[tag],
+		fallback: c.messages[DefaultLanguage],
+	}
+}
+
+// Lang returns the BCP 47 tag of the locale, for the html lang attribute
+func (l *Localizer) Lang() string {
+	return l.tag.String()
+}
+
+// T formats the message for key with args
+func (l *Localizer) T(key string, args ...interface{}) string {
+	m, ok := l.lookup(key)
+	if !ok {
+		return key
+	}
+	if m.plural != nil {
+		return l.format(m.plural["other"], args)
+	}
+	return l.format(m.text, args)
+}
+
+// N formats the plural form of key that matches count. count is passed to
+// the format string before args.
+func (l *Localizer) N(key string, count int, args ...interface{}) string {
+	m, ok := l.lookup(key)
+	if !ok {
+		return key
+	}
+	args = append([]interface{}{count}, args...)
+	if m.plural == nil {
+		return l.format(m.text, args)
+	}
+
+	n := count
+	if n < 0 {
+		n = -n
+	}
+	form := plural.Cardinal.MatchPlural(l.tag, n, 0, 0, 0, 0)
+	if text, ok := m.plural[pluralForms[form]]; ok {
+		return l.format(text, args)
+	}
+	return l.format(m.plural["other"], args)
+}
+
+func (l *Localizer) lookup(key string) (message, bool) {
+	if m, ok := l.messages[key]; ok {
+		return m, true
+	}
+	m, ok := l.fallback[key]
+	return m, ok
+}
+
+func (l *Localizer) format(text string, args []interface{}) string {
+	if len(args) == 0 {
+		return text
+	}
+	return fmt.Sprintf(text, args...)
+}
+
+var pluralForms = map[plural.Form]string{
+	plural.Zero:  "zero",
+	plural.One:   "one",
+	plural.Two:   "two",
+	plural.Few:   "few",
+	plural.Many:  "many",
+	plural.Other: "other",
+}
+
+type contextKey struct{}
+
+// WithLocalizer returns a copy of ctx carrying l
+func WithLocalizer(ctx context.Context, l *Localizer) context.Context {
+	return context.WithValue(ctx, contextKey{}, l)
+}
+
+// FromContext returns the localizer stored in ctx, or one for
+// DefaultLanguage
+func FromContext(ctx context.Context) *Localizer {
+	if l, ok := ctx.Value(contextKey{}).(*Localizer); ok {
+		return l
+	}
+	return defaultLocalizer
+}
+
+var defaultLocalizer = NewLocalizer()
+
+// T formats key in the locale of ctx. Templ components call it with their
+// implicit ctx.
+func T(ctx context.Context, key string, args ...interface{}) string {
+	return FromContext(ctx).T(key, args...)
+}
+
+// N formats the plural form of key for count in the locale of ctx
+func N(ctx context.Context, key string, count int, args ...interface{}) string {
+	return FromContext(ctx).N(key, count, args...)
+}
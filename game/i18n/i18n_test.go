@@ -0,0 +1,90 @@
+package i18n
+
+import (
+	"testing"
+
+	"golang.org/x/text/language"
+)
+
+func TestLocalizerPlural(t *testing.T) {
+	items := message{plural: map[string]string{"one": "%d item", "other": "%d items"}}
+	fallback := map[string]message{"items": items}
+	localizer := func(tag language.Tag) *Localizer {
+		return &Localizer{tag: tag, messages: map[string]message{"items": items}, fallback: fallback}
+	}
+
+	tests := []struct {
+		lang  language.Tag
+		count int
+		want  string
+	}{
+		{language.English, 0, "0 items"},
+		{language.English, 1, "1 item"},
+		{language.English, 2, "2 items"},
+		{language.English, -1, "-1 item"},
+		{language.English, 21, "21 items"},
+		{language.German, 0, "0 items"},
+		{language.German, 1, "1 item"},
+		{language.German, 2, "2 items"},
+		{language.Spanish, 0, "0 items"},
+		{language.Spanish, 1, "1 item"},
+		{language.Spanish, 2, "2 items"},
+		{language.Spanish, 1000000, "1000000 items"}, // "many" falls back to "other"
+	}
+	for _, tt := range tests {
+		t.Run(tt.lang.String(), func(t *testing.T) {
+			if got := localizer(tt.lang).N("items", tt.count); got != tt.want {
+				t.Errorf("N(%d) = %q, want %q", tt.count, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCatalogPlural(t *testing.T) {
+	tests := []struct {
+		lang  string
+		count int
+		want  string
+	}{
+		{"en", 1, "1 Active"},
+		{"en", 3, "3 Active"},
+		{"de", 1, "1 aktiv"},
+		{"de", 3, "3 aktiv"},
+		{"es", 0, "0 activos"},
+		{"es", 1, "1 activo"},
+		{"es", 3, "3 activos"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.lang, func(t *testing.T) {
+			if got := NewLocalizer(tt.lang).N("leaderboard.active", tt.count); got != tt.want {
+				t.Errorf("N(%d) = %q, want %q", tt.count, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLocalizerFallback(t *testing.T) {
+	tests := []struct {
+		name        string
+		preferences []string
+		lang        string
+	}{
+		{"exact", []string{"de"}, "de"},
+		{"region", []string{"es-MX"}, "es"},
+		{"quality order", []string{"fr;q=0.9, es;q=0.8"}, "es"},
+		{"unsupported", []string{"ja"}, "en"},
+		{"none", nil, "en"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := NewLocalizer(tt.preferences...)
+			if base, _ := language.Make(l.Lang()).Base(); base.String() != tt.lang {
+				t.Errorf("Lang() = %q, want %s", l.Lang(), tt.lang)
+			}
+		})
+	}
+
+	if got := NewLocalizer("de").T("no.such.key"); got != "no.such.key" {
+		t.Errorf("missing key = %q, want the key itself", got)
+	}
+}
@@ -0,0 +1,241 @@
+package main
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"server/auth"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// sessionCookieName holds the signed session token for browser clients
+const sessionCookieName = "player_session"
+
+// errNoCredentials means the request carried no session token at all
+var errNoCredentials = errors.New("no session token")
+
+// Global session token signer
+var sessionSigner *auth.Signer
+
+// newSessionSignerFromEnv builds the signer from SESSION_SECRET and SESSION_TTL.
+// Without a secret a random one is generated, so sessions end on restart.
+func newSessionSignerFromEnv() *auth.Signer {
+	secret := []byte(os.Getenv("SESSION_SECRET"))
+	if len(secret) == 0 {
+		log.Printf("⚠️ SESSION_SECRET not set, using a random secret (sessions will not survive restarts)")
+		secret = auth.RandomSecret()
+	}
+
+	ttl := 30 * 24 * time.Hour
+	if v := os.Getenv("SESSION_TTL"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d > 0 {
+			ttl = d
+		} else {
+			log.Printf("⚠️ Ignoring invalid SESSION_TTL=%q", v)
+		}
+	}
+
+	return auth.NewSigner(secret, ttl)
+}
+
+// requestSessionToken extracts a session token from the Authorization header
+// or the session cookie. Query tokens are only accepted for the webview
+// handoff on GET / and for MCP connections.
+func requestSessionToken(r *http.Request) string {
+	if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok && token != "" {
+		return token
+	}
+	if cookie, err := r.Cookie(sessionCookieName); err == nil {
+		return cookie.Value
+	}
+	return ""
+}
+
+// authenticatePlayer verifies the request's session token without minting a
+// new identity
+func authenticatePlayer(r *http.Request) (*auth.Claims, error) {
+	token := requestSessionToken(r)
+	if token == "" {
+		return nil, errNoCredentials
+	}
+	return sessionSigner.Verify(token)
+}
+
+// getPlayerID returns the verified player ID for the request, issuing a new
+// signed identity cookie to browsers that do not have a valid one. API and MCP
+// callers use authenticatePlayer, which rejects stale tokens instead.
+func getPlayerID(w http.ResponseWriter, r *http.Request) (string, error) {
+	var claims *auth.Claims
+	var err error
+	handoff := r.URL.Query().Get("token")
+	if handoff != "" {
+		claims, err = sessionSigner.Verify(handoff)
+	} else {
+		claims, err = authenticatePlayer(r)
+	}
+	if err == nil {
+		// Persist the webview handoff token and refresh ageing cookies
+		if handoff != "" || time.Until(claims.Expiry()) < sessionSigner.TTL()/2 {
+			issueSessionCookie(w, r, claims.PlayerID)
+		}
+		return claims.PlayerID, nil
+	}
+	if errors.Is(err, auth.ErrInvalidToken) || errors.Is(err, auth.ErrExpiredToken) {
+		// Stale cookie, e.g. signed with a previous random secret: start over
+		// as a new player instead of locking the browser out
+		clearSessionCookie(w, r)
+	} else if !errors.Is(err, errNoCredentials) {
+		return "", err
+	}
+
+	if ok, retryAfter := rateLimiter.AllowNewIdentity(r); !ok {
+		return "", &RateLimitError{RetryAfter: retryAfter}
+	}
+
+	playerID := uuid.New().String()[:8]
+	if err := issueSessionCookie(w, r, playerID); err != nil {
+		return "", err
+	}
+
+	log.Printf("🆕 New player created: %s", playerID)
+	return playerID, nil
+}
+
+// issueSessionCookie signs a fresh token for playerID and stores it in a cookie
+func issueSessionCookie(w http.ResponseWriter, r *http.Request, playerID string) error {
+	token, _, err := sessionSigner.Issue(playerID)
+	if err != nil {
+		return err
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     sessionCookieName,
+		Value:    token,
+		Path:     "/",
+		HttpOnly: true,
+		Secure:   r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https",
+		SameSite: http.SameSiteLaxMode,
+		MaxAge:   int(sessionSigner.TTL().Seconds()),
+	})
+	return nil
+}
+
+// redactTokenQuery hides the token query parameter from request logs; it
+// must run before the logger
+func redactTokenQuery(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if query := r.URL.Query(); query.Has("token") {
+			query.Set("token", "redacted")
+			uri := *r.URL
+			uri.RawQuery = query.Encode()
+			r.RequestURI = uri.RequestURI()
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// clearSessionCookie expires the session cookie
+func clearSessionCookie(w http.ResponseWriter, r *http.Request) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     sessionCookieName,
+		Value:    "",
+		Path:     "/",
+		HttpOnly: true,
+		Secure:   r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https",
+		SameSite: http.SameSiteLaxMode,
+		MaxAge:   -1,
+	})
+}
+
+// sessionTokenForPage returns a token the rendered page sends as an
+// Authorization header on SSE and action requests, for embedded webviews
+// where cookies may not be stored
+func sessionTokenForPage(playerID string) string {
+	token, _, err := sessionSigner.Issue(playerID)
+	if err != nil {
+		log.Printf("❌ Failed to issue session token for %s: %v", playerID, err)
+		return ""
+	}
+	return token
+}
+
+// requireSelf rejects requests whose verified identity is not the {playerID}
+// in the route
+func requireSelf(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		claims, err := authenticatePlayer(r)
+		if err != nil {
+			writePlayerIDError(w, err)
+			return
+		}
+		if claims.PlayerID != chi.URLParam(r, "playerID") {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// writePlayerIDError maps an identity failure to an HTTP response
+func writePlayerIDError(w http.ResponseWriter, err error) {
+	var rle *RateLimitError
+	switch {
+	case errors.As(err, &rle):
+		writeRateLimited(w, rle.RetryAfter)
+	case errors.Is(err, errNoCredentials), errors.Is(err, auth.ErrInvalidToken), errors.Is(err, auth.ErrExpiredToken):
+		http.Error(w, "Unauthorized: "+err.Error(), http.StatusUnauthorized)
+	default:
+		http.Error(w, "Failed to get player ID", http.StatusInternalServerError)
+	}
+}
+
+// SetupSessionRoutes mounts the token-exchange endpoint used by the Slide Code
+// host to obtain a signed identity for its embedded webview. The host
+// authenticates with HOST_API_KEY; the endpoint is disabled without one.
+func SetupSessionRoutes(router chi.Router, hostAPIKey string) {
+	if hostAPIKey == "" {
+		log.Printf("⚠️ HOST_API_KEY not set, session token exchange disabled")
+		return
+	}
+
+	router.With(rateLimiter.Limit).Post("/api/session/exchange", func(w http.ResponseWriter, r *http.Request) {
+		provided, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(provided), []byte(hostAPIKey)) != 1 {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		var body struct {
+			UserID string `json:"userId"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.UserID == "" {
+			http.Error(w, "userId is required", http.StatusBadRequest)
+			return
+		}
+
+		token, claims, err := sessionSigner.Issue(body.UserID)
+		if err != nil {
+			log.Printf("❌ Failed to issue session token: %v", err)
+			http.Error(w, "Failed to issue token", http.StatusInternalServerError)
+			return
+		}
+
+		log.Printf("🔑 Issued session token for %s", body.UserID)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"token":     token,
+			"playerId":  claims.PlayerID,
+			"expiresAt": claims.ExpiresAt,
+		})
+	})
+
+	log.Printf("🔑 Session token exchange enabled at /api/session/exchange")
+}
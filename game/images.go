@@ -0,0 +1,303 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/png"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"server/types"
+
+	"github.com/go-chi/chi/v5"
+)
+
+const (
+	// Pixel size of one grid cell in /grid.png and timelapse frames
+	gridImageCellSize = 16
+	timelapseCellSize = 8
+
+	// Timelapses are sampled down to at most this many frames
+	timelapseMaxFrames = 120
+
+	// GIF frame delays in hundredths of a second
+	timelapseFrameDelay = 8
+	timelapseFinalDelay = 300
+
+	// Number of rendered images kept per cache
+	imageCacheSize = 16
+
+	// A live round's timelapse is rendered at most once per bucket
+	timelapseLiveBucket = 10 * time.Second
+
+	// Longest a timelapse may take to replay and encode
+	timelapseRenderTimeout = 30 * time.Second
+)
+
+var (
+	gridImages      = newImageCache(imageCacheSize)
+	timelapseImages = newImageCache(imageCacheSize)
+)
+
+// SetupImageRoutes mounts /grid.png and /rounds/{roundID}/timelapse.gif for
+// share cards, chat bots and thumbnails
+func SetupImageRoutes(router chi.Router) {
+	router.With(rateLimiter.Limit).Get("/grid.png", func(w http.ResponseWriter, r *http.Request) {
+		frame := broadcastHub.Latest()
+		if frame == nil {
+			http.Error(w, "Game state not available yet", http.StatusServiceUnavailable)
+			return
+		}
+
+		key := strconv.FormatUint(frame.version, 10)
+		data, err := gridImages.getOrRender(key, func() ([]byte, error) {
+			return renderGridPNG(frame.cells)
+		})
+		if err != nil {
+			log.Printf("❌ Failed to render grid image: %v", err)
+			http.Error(w, "Failed to render grid", http.StatusInternalServerError)
+			return
+		}
+
+		writeImage(w, r, "image/png", key, data)
+	})
+
+	router.With(rateLimiter.Limit).Get("/rounds/{roundID}/timelapse.gif", func(w http.ResponseWriter, r *http.Request) {
+		roundID, err := strconv.Atoi(chi.URLParam(r, "roundID"))
+		if err != nil || roundID <= 0 {
+			http.Error(w, "Invalid round ID", http.StatusBadRequest)
+			return
+		}
+
+		record, err := natsGameManager.GetRound(roundID)
+		if errors.Is(err, types.ErrRoundNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			log.Printf("❌ Failed to load round %d: %v", roundID, err)
+			http.Error(w, "Failed to load round", http.StatusInternalServerError)
+			return
+		}
+
+		// Finished rounds never change. Every placement changes a live round,
+		// so it is re-rendered at most once per timelapseLiveBucket.
+		key := fmt.Sprintf("%d:final", roundID)
+		if !record.Finished() {
+			key = fmt.Sprintf("%d:live:%d", roundID, time.Now().UnixNano()/int64(timelapseLiveBucket))
+		}
+
+		data, err := timelapseImages.getOrRender(key, func() ([]byte, error) {
+			// Shared by every request waiting on this key, so not tied to r
+			ctx, cancel := context.WithTimeout(context.Background(), timelapseRenderTimeout)
+			defer cancel()
+			return renderTimelapseGIF(ctx, roundID)
+		})
+		if errors.Is(err, types.ErrRoundEventsExpired) {
+			http.Error(w, err.Error(), http.StatusGone)
+			return
+		}
+		if errors.Is(err, types.ErrRoundNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			log.Printf("❌ Failed to render timelapse for round %d: %v", roundID, err)
+			http.Error(w, "Failed to render timelapse", http.StatusInternalServerError)
+			return
+		}
+
+		writeImage(w, r, "image/gif", key, data)
+	})
+}
+
+// renderGridPNG draws each cell as a square in its Cell.Color
+func renderGridPNG(cells map[string]types.Cell) ([]byte, error) {
+	img := image.NewRGBA(image.Rect(0, 0, types.GridWidth*gridImageCellSize, types.GridHeight*gridImageCellSize))
+	for y := 0; y < types.GridHeight; y++ {
+		for x := 0; x < types.GridWidth; x++ {
+			c := parseHexColor(cells[fmt.Sprintf("%d:%d", x, y)].Color)
+			fillCell(img, x, y, gridImageCellSize, c)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("failed to encode png: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// renderTimelapseGIF replays a round's placements from an empty grid,
+// sampling frames evenly so the animation stays short
+func renderTimelapseGIF(ctx context.Context, roundID int) ([]byte, error) {
+	var events []*types.RoundEvent
+	err := natsGameManager.ReplayRound(ctx, roundID, func(event *types.RoundEvent) error {
+		events = append(events, event)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Palette index 0 is neutral; team colors are added as they appear
+	palette := color.Palette{parseHexColor(types.NeutralCellColor)}
+	paletteIndex := map[string]uint8{types.NeutralCellColor: 0}
+	indexFor := func(hex string) uint8 {
+		if i, ok := paletteIndex[hex]; ok {
+			return i
+		}
+		if len(palette) == 256 {
+			return 0
+		}
+		palette = append(palette, parseHexColor(hex))
+		paletteIndex[hex] = uint8(len(palette) - 1)
+		return paletteIndex[hex]
+	}
+	for _, event := range events {
+		if event.Kind == types.RoundEventBitPlaced {
+			indexFor(event.Color)
+		}
+	}
+
+	grid := make([]uint8, types.GridWidth*types.GridHeight)
+	anim := &gif.GIF{}
+	addFrame := func(delay int) {
+		frame := image.NewPaletted(image.Rect(0, 0, types.GridWidth*timelapseCellSize, types.GridHeight*timelapseCellSize), palette)
+		for y := 0; y < types.GridHeight; y++ {
+			for x := 0; x < types.GridWidth; x++ {
+				fillCell(frame, x, y, timelapseCellSize, palette[grid[y*types.GridWidth+x]])
+			}
+		}
+		anim.Image = append(anim.Image, frame)
+		anim.Delay = append(anim.Delay, delay)
+	}
+
+	step := (len(events) + timelapseMaxFrames - 1) / timelapseMaxFrames
+	if step < 1 {
+		step = 1
+	}
+
+	addFrame(timelapseFrameDelay)
+	for i, event := range events {
+		switch event.Kind {
+		case types.RoundEventGridReset:
+			clear(grid)
+		case types.RoundEventBitPlaced:
+			if event.X >= 0 && event.X < types.GridWidth && event.Y >= 0 && event.Y < types.GridHeight {
+				grid[event.Y*types.GridWidth+event.X] = indexFor(event.Color)
+			}
+		}
+		if (i+1)%step == 0 && i+1 < len(events) {
+			addFrame(timelapseFrameDelay)
+		}
+	}
+	addFrame(timelapseFinalDelay)
+
+	var buf bytes.Buffer
+	if err := gif.EncodeAll(&buf, anim); err != nil {
+		return nil, fmt.Errorf("failed to encode gif: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func fillCell(img interface{ Set(x, y int, c color.Color) }, x, y, size int, c color.Color) {
+	for py := 0; py < size; py++ {
+		for px := 0; px < size; px++ {
+			img.Set(x*size+px, y*size+py, c)
+		}
+	}
+}
+
+// parseHexColor parses "#rrggbb", falling back to the neutral cell color
+func parseHexColor(hex string) color.RGBA {
+	var r, g, b uint8
+	if _, err := fmt.Sscanf(hex, "#%02x%02x%02x", &r, &g, &b); err != nil {
+		if hex != types.NeutralCellColor {
+			return parseHexColor(types.NeutralCellColor)
+		}
+		return color.RGBA{A: 255}
+	}
+	return color.RGBA{R: r, G: g, B: b, A: 255}
+}
+
+// writeImage sends image bytes with an ETag derived from the cache key
+func writeImage(w http.ResponseWriter, r *http.Request, contentType, key string, data []byte) {
+	etag := strconv.Quote(key)
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", "public, max-age=1")
+	w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+	w.Write(data)
+}
+
+// imageCache keeps the most recently rendered images by key
+type imageCache struct {
+	mu       sync.Mutex
+	size     int
+	entries  map[string][]byte
+	order    []string // oldest first
+	inflight map[string]*imageRender
+}
+
+// imageRender is a render other requests for the same key wait on
+type imageRender struct {
+	done chan struct{}
+	data []byte
+	err  error
+}
+
+func newImageCache(size int) *imageCache {
+	return &imageCache{
+		size:     size,
+		entries:  make(map[string][]byte),
+		inflight: make(map[string]*imageRender),
+	}
+}
+
+// getOrRender returns the cached image for key, rendering it on a miss.
+// Concurrent misses for the same key share one render.
+func (c *imageCache) getOrRender(key string, render func() ([]byte, error)) ([]byte, error) {
+	c.mu.Lock()
+	if data, ok := c.entries[key]; ok {
+		c.mu.Unlock()
+		return data, nil
+	}
+	if pending, ok := c.inflight[key]; ok {
+		c.mu.Unlock()
+		<-pending.done
+		return pending.data, pending.err
+	}
+	pending := &imageRender{done: make(chan struct{})}
+	c.inflight[key] = pending
+	c.mu.Unlock()
+
+	pending.data, pending.err = render()
+
+	c.mu.Lock()
+	delete(c.inflight, key)
+	if pending.err == nil {
+		c.entries[key] = pending.data
+		c.order = append(c.order, key)
+		if len(c.order) > c.size {
+			delete(c.entries, c.order[0])
+			c.order = c.order[1:]
+		}
+	}
+	c.mu.Unlock()
+	close(pending.done)
+	return pending.data, pending.err
+}
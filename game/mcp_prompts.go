@@ -0,0 +1,323 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"server/types"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Prompts that bootstrap a game-playing agent. Each is written for the
+// session's player and embeds live state from the game manager.
+
+// maxPlannedMoves caps the moves argument of plan_next_moves
+const maxPlannedMoves = 50
+
+// setupGamePrompts configures the MCP prompts
+func (gs *MCPGameServer) setupGamePrompts() {
+	userArg := mcp.WithArgument("user_id",
+		mcp.ArgumentDescription("The ID of the player the agent plays as; must be the session's player if given"),
+	)
+
+	gs.mcpServer.AddPrompt(mcp.NewPrompt("explain_rules",
+		mcp.WithPromptDescription("Explain the rules of BitSplat and how to play it through the MCP tools"),
+		userArg,
+	), gs.handleExplainRulesPrompt)
+
+	gs.mcpServer.AddPrompt(mcp.NewPrompt("analyze_board",
+		mcp.WithPromptDescription("Analyze the current board from the point of view of a team"),
+		userArg,
+		mcp.WithArgument("team_id",
+			mcp.ArgumentDescription("Team to analyze for (default the player's team)"),
+		),
+	), gs.handleAnalyzeBoardPrompt)
+
+	gs.mcpServer.AddPrompt(mcp.NewPrompt("plan_next_moves",
+		mcp.WithPromptDescription("Plan the player's next moves and place them with place_bits"),
+		userArg,
+		mcp.WithArgument("moves",
+			mcp.ArgumentDescription(fmt.Sprintf("Number of moves to plan (default 5, at most %d)", maxPlannedMoves)),
+		),
+	), gs.handlePlanNextMovesPrompt)
+
+	gs.mcpServer.AddPrompt(mcp.NewPrompt("post_round_review",
+		mcp.WithPromptDescription("Review a finished round: results, the player's contribution and lessons for the next round"),
+		userArg,
+		mcp.WithArgument("round_id",
+			mcp.ArgumentDescription("Round to review (default the latest finished round)"),
+		),
+	), gs.handlePostRoundReviewPrompt)
+}
+
+// promptPlayer looks up the session's player. An explicit user_id must name
+// that player.
+func (gs *MCPGameServer) promptPlayer(ctx context.Context, request mcp.GetPromptRequest) (*types.Player, *types.Team, error) {
+	playerID, err := gs.sessionPlayer(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	if userID := request.Params.Arguments["user_id"]; userID != "" && userID != playerID {
+		return nil, nil, fmt.Errorf("user_id %s is not the player of this session", userID)
+	}
+	player, team := gs.gameManager.GetPlayer(playerID)
+	if player == nil {
+		return nil, nil, fmt.Errorf("player %s not found; join the game first with add_player", playerID)
+	}
+	return player, team, nil
+}
+
+// userPrompt wraps text as a single user message
+func userPrompt(description, text string) *mcp.GetPromptResult {
+	return mcp.NewGetPromptResult(description, []mcp.PromptMessage{
+		mcp.NewPromptMessage(mcp.RoleUser, mcp.NewTextContent(text)),
+	})
+}
+
+// roundSummary describes the round state and the teams ranked by score
+func roundSummary(gameState *types.GameState) string {
+	var s strings.Builder
+	fmt.Fprintf(&s, "Round %d: %s", gameState.RoundID, gameState.RoundState)
+	switch {
+	case gameState.Paused:
+		s.WriteString(" (paused)")
+	case gameState.RoundState == types.InProgress:
+		fmt.Fprintf(&s, ", %s remaining", gameState.RoundTimeRemaining.Round(time.Second))
+	case gameState.RoundState == types.Waiting:
+		fmt.Fprintf(&s, ", starts in %s", gameState.Countdown.Round(time.Second))
+	}
+	s.WriteString("\nScores:\n")
+
+	teams := make([]*types.Team, 0, len(gameState.Teams))
+	for _, team := range gameState.Teams {
+		teams = append(teams, team)
+	}
+	sort.Slice(teams, func(i, j int) bool {
+		if teams[i].Score != teams[j].Score {
+			return teams[i].Score > teams[j].Score
+		}
+		return teams[i].ID < teams[j].ID
+	})
+	for _, team := range teams {
+		fmt.Fprintf(&s, "- %s: %d cells (%.1f%%), %d active and %d idle players\n",
+			team.ID, team.Score, team.Percentage, team.ActivePlayers, team.IdlePlayers)
+	}
+	return s.String()
+}
+
+// boardSummary is the round summary plus the whole grid as a letter matrix
+func boardSummary(gameState *types.GameState) string {
+	region, _ := newGridRegionJSON(gameState, 0, 0, types.GridWidth-1, types.GridHeight-1)
+	return fmt.Sprintf("%s\nGrid (%dx%d, x left to right, y top to bottom):\n%s\n",
+		roundSummary(gameState), types.GridWidth, types.GridHeight, gridMatrix(region))
+}
+
+func (gs *MCPGameServer) handleExplainRulesPrompt(ctx context.Context, request mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+	player, team, err := gs.promptPlayer(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	gameState, err := gs.gameManager.GetGameState()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get game state: %w", err)
+	}
+
+	text := fmt.Sprintf(`You are playing BitSplat as player %s on team %s.
+
+Rules:
+- The grid is %d cells wide and %d cells high. Every cell is neutral or owned by one of the teams %s.
+- Placing a bit on a cell costs 1 bit and claims the cell for your team, taking it from whoever owned it. Placing on a cell your team already owns does nothing and costs nothing.
+- You hold at most %d bits and regain %d every %s while a round is running. After each placement you must wait %s before the next.
+- A round lasts %s. The team owning the most cells when time runs out wins. A new round starts %s after the last one ends, following a %s countdown, with a fresh grid and full bits.
+- Bits can only be placed while a round is in progress and not paused.
+
+Tools:
+- get_game_state, get_team_info and get_grid_region show the board; find_cells finds neutral, enemy, own or border cells.
+- get_recent_events shows the latest captures; get_heatmap shows the most contested cells.
+- get_cooldown_status tells you when you can act and when your next bit arrives.
+- place_bit places one bit; place_bits places up to %d in order, waiting out the cooldown for you.
+Tools act as the player this MCP session connected as.
+
+Right now: %s
+You have %d bits.
+
+Explain these rules back briefly, then suggest an opening strategy for team %s.`,
+		player.ID, team.ID,
+		types.GridWidth, types.GridHeight, strings.Join(types.TeamIDs, ", "),
+		types.MaxBits, types.BitsPerTick, types.GameTickRate, types.ActionCooldown,
+		types.RoundDuration, types.PostRoundDelay, types.PreRoundCountdown,
+		maxPlaceBatch,
+		roundSummary(gameState),
+		player.Bits, team.ID)
+
+	return userPrompt("BitSplat rules for "+player.ID, text), nil
+}
+
+func (gs *MCPGameServer) handleAnalyzeBoardPrompt(ctx context.Context, request mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+	player, team, err := gs.promptPlayer(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	gameState, err := gs.gameManager.GetGameState()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get game state: %w", err)
+	}
+
+	teamID := request.Params.Arguments["team_id"]
+	if teamID == "" {
+		teamID = team.ID
+	}
+	if _, ok := gameState.Teams[teamID]; !ok {
+		return nil, fmt.Errorf("team %s not found", teamID)
+	}
+
+	hot := contestHeatmap.Snapshot(HeatmapModeChanges)
+	var hotspots strings.Builder
+	for i, c := range hot.Cells {
+		if i == 10 || c.Changes == 0 {
+			break
+		}
+		fmt.Fprintf(&hotspots, "- (%d, %d): %d hand changes\n", c.X, c.Y, c.Changes)
+	}
+	if hotspots.Len() == 0 {
+		hotspots.WriteString("- none yet\n")
+	}
+
+	text := fmt.Sprintf(`You are player %s. Analyze the BitSplat board for team %s.
+
+%s
+Most contested cells this round:
+%s
+Cover: where team %s is strong or exposed, which areas are neutral and cheap to take, which rival is the biggest threat, and where contested cells suggest a fight is not worth it. Finish with three concrete priorities for the team. Use find_cells with the border filter for exact frontier cells.`,
+		player.ID, teamID, boardSummary(gameState), hotspots.String(), teamID)
+
+	return userPrompt("Board analysis for team "+teamID, text), nil
+}
+
+func (gs *MCPGameServer) handlePlanNextMovesPrompt(ctx context.Context, request mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+	player, team, err := gs.promptPlayer(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	moves := 5
+	if v := request.Params.Arguments["moves"]; v != "" {
+		moves, err = strconv.Atoi(v)
+		if err != nil || moves < 1 {
+			return nil, fmt.Errorf("moves must be a positive integer")
+		}
+		moves = min(moves, maxPlannedMoves)
+	}
+
+	gameState, err := gs.gameManager.GetGameState()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get game state: %w", err)
+	}
+	status, err := gs.gameManager.GetCooldownStatus(player.ID)
+	if err != nil {
+		return nil, err
+	}
+	cooldown := newCooldownJSON(status)
+
+	bits := fmt.Sprintf("You have %d of %d bits", cooldown.Bits, cooldown.MaxBits)
+	if cooldown.NextBitIn >= 0 {
+		bits += fmt.Sprintf(" and get the next one in %.1fs", cooldown.NextBitIn)
+	}
+
+	text := fmt.Sprintf(`You are player %s on team %s. Plan your next %d moves in BitSplat.
+
+%s
+%s.
+
+Prefer neutral cells and enemy cells next to your territory, grow connected regions, and avoid spending bits on cells the other teams keep retaking. Plans longer than your bits have to wait for regeneration, so order the moves by value.
+
+List the %d cells as (x, y) with a one-line reason each, then place the first batch with place_bits (at most %d cells, as many as you have bits).`,
+		player.ID, team.ID, moves, boardSummary(gameState), bits, moves, maxPlaceBatch)
+
+	return userPrompt(fmt.Sprintf("Next %d moves for %s", moves, player.ID), text), nil
+}
+
+func (gs *MCPGameServer) handlePostRoundReviewPrompt(ctx context.Context, request mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+	player, team, err := gs.promptPlayer(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	roundID := 0
+	if v := request.Params.Arguments["round_id"]; v != "" {
+		if roundID, err = strconv.Atoi(v); err != nil {
+			return nil, fmt.Errorf("round_id must be an integer")
+		}
+	} else {
+		gameState, err := gs.gameManager.GetGameState()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get game state: %w", err)
+		}
+		roundID = gameState.RoundID
+		if gameState.RoundState != types.Finished {
+			roundID--
+		}
+	}
+
+	record, err := gs.gameManager.GetRound(roundID)
+	if errors.Is(err, types.ErrRoundNotFound) {
+		return nil, fmt.Errorf("round %d not found; no round has finished yet", roundID)
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to get round: %w", err)
+	}
+	if !record.Finished() {
+		return nil, fmt.Errorf("round %d has not finished yet", roundID)
+	}
+
+	// Tally captures per team and the player's own placements
+	captures := make(map[string]int)
+	steals := make(map[string]int)
+	mine, mineStolen := 0, 0
+	err = gs.gameManager.ReplayRound(ctx, roundID, func(e *types.RoundEvent) error {
+		if e.Kind != types.RoundEventBitPlaced {
+			return nil
+		}
+		stolen := e.OldOwner != "" && e.OldOwner != types.NeutralOwnerID
+		captures[e.TeamID]++
+		if stolen {
+			steals[e.TeamID]++
+		}
+		if e.PlayerID == player.ID {
+			mine++
+			if stolen {
+				mineStolen++
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to replay round %d: %w", roundID, err)
+	}
+
+	var results strings.Builder
+	winner := record.Winner
+	if winner == "" {
+		winner = "nobody"
+	}
+	fmt.Fprintf(&results, "Round %d lasted %s and was won by %s.\n",
+		record.ID, record.EndedAt.Sub(record.StartedAt).Round(time.Second), winner)
+	for _, id := range types.TeamIDs {
+		fmt.Fprintf(&results, "- %s: %d cells at the end, %d captures of which %d taken from other teams\n",
+			id, record.Scores[id], captures[id], steals[id])
+	}
+
+	text := fmt.Sprintf(`You are player %s on team %s. Review BitSplat round %d.
+
+%s
+You placed %d bits, %d of them on cells another team owned.
+
+Explain why the round went the way it did, how your placements helped team %s, what the winning team did well, and two things to do differently next round.`,
+		player.ID, team.ID, roundID, results.String(), mine, mineStolen, team.ID)
+
+	return userPrompt(fmt.Sprintf("Review of round %d for %s", roundID, player.ID), text), nil
+}
@@ -0,0 +1,255 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"server/types"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"golang.org/x/time/rate"
+)
+
+// MCP tool calls have their own quotas, separate from the HTTP rate limits:
+// calls per minute for each session and each player, and bit placements per
+// round for each player. Every call, including refused ones, is written to the
+// MCP_AUDIT stream.
+
+// MCPQuotaConfig controls the MCP tool call quotas. A zero value disables the
+// corresponding quota.
+type MCPQuotaConfig struct {
+	SessionCallsPerMinute int
+	PlayerCallsPerMinute  int
+	PlacementsPerRound    int
+
+	// Buckets unused for this long are evicted
+	IdleTTL time.Duration
+}
+
+// DefaultMCPQuotaConfig returns quotas that leave room for an agent polling
+// state between moves. A round regenerates about 45 bits on top of MaxBits, so
+// the placement quota only bites on granted bits and retried failures.
+func DefaultMCPQuotaConfig() *MCPQuotaConfig {
+	return &MCPQuotaConfig{
+		SessionCallsPerMinute: 120,
+		PlayerCallsPerMinute:  240,
+		PlacementsPerRound:    60,
+		IdleTTL:               10 * time.Minute,
+	}
+}
+
+// MCPQuotaConfigFromEnv applies MCP_*_CALLS_PER_MINUTE and
+// MCP_PLACEMENTS_PER_ROUND overrides to the defaults
+func MCPQuotaConfigFromEnv() *MCPQuotaConfig {
+	config := DefaultMCPQuotaConfig()
+	config.SessionCallsPerMinute = envInt("MCP_SESSION_CALLS_PER_MINUTE", config.SessionCallsPerMinute)
+	config.PlayerCallsPerMinute = envInt("MCP_PLAYER_CALLS_PER_MINUTE", config.PlayerCallsPerMinute)
+	config.PlacementsPerRound = envInt("MCP_PLACEMENTS_PER_ROUND", config.PlacementsPerRound)
+	return config
+}
+
+// roundPlacements counts a player's placements in one round
+type roundPlacements struct {
+	roundID int
+	count   int
+}
+
+// MCPQuotas holds the per-session and per-player MCP quotas
+type MCPQuotas struct {
+	config      *MCPQuotaConfig
+	gameManager types.NATSManager
+	sessions    *keyedLimiter
+	players     *keyedLimiter
+
+	mu         sync.Mutex
+	placements map[string]*roundPlacements // player ID -> current round count
+}
+
+// NewMCPQuotas creates the quotas and evicts idle buckets until ctx is done
+func NewMCPQuotas(ctx context.Context, gameManager types.NATSManager, config *MCPQuotaConfig) *MCPQuotas {
+	if config == nil {
+		config = DefaultMCPQuotaConfig()
+	}
+
+	q := &MCPQuotas{
+		config:      config,
+		gameManager: gameManager,
+		placements:  make(map[string]*roundPlacements),
+	}
+	if config.SessionCallsPerMinute > 0 {
+		q.sessions = newKeyedLimiter(rate.Every(time.Minute/time.Duration(config.SessionCallsPerMinute)), config.SessionCallsPerMinute)
+	}
+	if config.PlayerCallsPerMinute > 0 {
+		q.players = newKeyedLimiter(rate.Every(time.Minute/time.Duration(config.PlayerCallsPerMinute)), config.PlayerCallsPerMinute)
+	}
+
+	go func() {
+		ticker := time.NewTicker(config.IdleTTL)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if q.sessions != nil {
+					q.sessions.evict(config.IdleTTL)
+				}
+				if q.players != nil {
+					q.players.evict(config.IdleTTL)
+				}
+			}
+		}
+	}()
+
+	log.Printf("🚦 MCP quotas enabled: %d calls/min per session, %d calls/min per player, %d placements per round",
+		config.SessionCallsPerMinute, config.PlayerCallsPerMinute, config.PlacementsPerRound)
+	return q
+}
+
+// allowCall takes a call from the session and player buckets
+func (q *MCPQuotas) allowCall(sessionID, playerID string) error {
+	if q.sessions != nil && sessionID != "" {
+		if ok, retryAfter := q.sessions.allow(sessionID); !ok {
+			return fmt.Errorf("MCP session call quota exceeded (%d per minute), retry after %v",
+				q.config.SessionCallsPerMinute, retryAfter.Round(time.Second))
+		}
+	}
+	if q.players != nil && playerID != "" {
+		if ok, retryAfter := q.players.allow(playerID); !ok {
+			return fmt.Errorf("MCP player call quota exceeded (%d per minute), retry after %v",
+				q.config.PlayerCallsPerMinute, retryAfter.Round(time.Second))
+		}
+	}
+	return nil
+}
+
+// reservePlacements counts n placements against the player's quota for the
+// current round. Reservations are not returned when placements fail, so
+// retrying a rejected move still costs quota.
+func (q *MCPQuotas) reservePlacements(playerID string, n int) error {
+	if q.config.PlacementsPerRound <= 0 || n == 0 || playerID == "" {
+		return nil
+	}
+	gameState, err := q.gameManager.GetGameState()
+	if err != nil {
+		return fmt.Errorf("failed to get game state: %w", err)
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	used, ok := q.placements[playerID]
+	if !ok || used.roundID != gameState.RoundID {
+		used = &roundPlacements{roundID: gameState.RoundID}
+		q.placements[playerID] = used
+	}
+	if used.count+n > q.config.PlacementsPerRound {
+		return fmt.Errorf("MCP placement quota exceeded: %d of %d placements used this round, %d requested",
+			used.count, q.config.PlacementsPerRound, n)
+	}
+	used.count += n
+	return nil
+}
+
+// toolPlacements returns how many bits a tool call tries to place
+func toolPlacements(request mcp.CallToolRequest) int {
+	switch request.Params.Name {
+	case "place_bit":
+		return 1
+	case "place_bits":
+		cells, _ := request.GetArguments()["cells"].([]any)
+		return len(cells)
+	}
+	return 0
+}
+
+// auditSecretArguments are argument names whose values never reach the audit
+// stream
+var auditSecretArguments = []string{"token", "password", "secret", "api_key"}
+
+// auditArguments returns the tool arguments with secret values redacted
+func auditArguments(raw any) any {
+	args, ok := raw.(map[string]any)
+	if !ok {
+		return raw
+	}
+	redacted := make(map[string]any, len(args))
+	for name, value := range args {
+		if slices.ContainsFunc(auditSecretArguments, func(secret string) bool {
+			return strings.Contains(strings.ToLower(name), secret)
+		}) {
+			value = "[redacted]"
+		}
+		redacted[name] = value
+	}
+	return redacted
+}
+
+// quotaMiddleware enforces the quotas around every tool handler and records each
+// call with its arguments, outcome and latency
+func (gs *MCPGameServer) quotaMiddleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		start := time.Now()
+		record := &types.MCPCallRecord{
+			At:         start,
+			Tool:       request.Params.Name,
+			Placements: toolPlacements(request),
+		}
+		if session := server.ClientSessionFromContext(ctx); session != nil {
+			record.SessionID = session.SessionID()
+		}
+		record.PlayerID, _ = gs.sessionPlayer(ctx)
+		if args, err := json.Marshal(auditArguments(request.GetRawArguments())); err == nil {
+			record.Arguments = args
+		}
+
+		var result *mcp.CallToolResult
+		var err error
+		quotaErr := gs.quotas.allowCall(record.SessionID, record.PlayerID)
+		if quotaErr == nil {
+			quotaErr = gs.quotas.reservePlacements(record.PlayerID, record.Placements)
+		}
+		if quotaErr != nil {
+			record.Outcome = types.MCPOutcomeRejected
+			record.Error = quotaErr.Error()
+			log.Printf("🚦 Refused MCP %s for player %s: %v", record.Tool, record.PlayerID, quotaErr)
+			result = mcp.NewToolResultError(quotaErr.Error())
+		} else {
+			result, err = next(ctx, request)
+			switch {
+			case err != nil:
+				record.Outcome = types.MCPOutcomeError
+				record.Error = err.Error()
+			case result != nil && result.IsError:
+				record.Outcome = types.MCPOutcomeError
+				record.Error = toolResultText(result)
+			default:
+				record.Outcome = types.MCPOutcomeOK
+			}
+		}
+
+		record.LatencyMs = float64(time.Since(start).Microseconds()) / 1000
+		if err := gs.gameManager.RecordMCPCall(record); err != nil {
+			log.Printf("⚠️ %v", err)
+		}
+		return result, err
+	}
+}
+
+// toolResultText joins the text content of a tool result
+func toolResultText(result *mcp.CallToolResult) string {
+	var text string
+	for _, content := range result.Content {
+		if tc, ok := content.(mcp.TextContent); ok {
+			text += tc.Text
+		}
+	}
+	return text
+}
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"server/types"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// roundManager is a game manager that only reports the current round
+type roundManager struct {
+	types.NATSManager
+	roundID int
+}
+
+func (m *roundManager) GetGameState() (*types.GameState, error) {
+	return &types.GameState{RoundID: m.roundID}, nil
+}
+
+func newTestQuotas(t *testing.T, manager types.NATSManager, config *MCPQuotaConfig) *MCPQuotas {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	config.IdleTTL = time.Minute
+	return NewMCPQuotas(ctx, manager, config)
+}
+
+func TestAllowCall(t *testing.T) {
+	type call struct {
+		session, player string
+		wantErr         string
+	}
+	tests := []struct {
+		name   string
+		config MCPQuotaConfig
+		calls  []call
+	}{
+		{
+			name:   "session quota",
+			config: MCPQuotaConfig{SessionCallsPerMinute: 2, PlayerCallsPerMinute: 10},
+			calls: []call{
+				{"s1", "p1", ""},
+				{"s1", "p1", ""},
+				{"s1", "p1", "session call quota exceeded (2 per minute)"},
+				{"s2", "p1", ""},
+			},
+		},
+		{
+			name:   "player quota spans sessions",
+			config: MCPQuotaConfig{SessionCallsPerMinute: 10, PlayerCallsPerMinute: 2},
+			calls: []call{
+				{"s1", "p1", ""},
+				{"s2", "p1", ""},
+				{"s3", "p1", "player call quota exceeded (2 per minute)"},
+				{"s3", "p2", ""},
+			},
+		},
+		{
+			name:   "disabled",
+			config: MCPQuotaConfig{},
+			calls:  []call{{"s1", "p1", ""}, {"s1", "p1", ""}, {"s1", "p1", ""}},
+		},
+		{
+			name:   "stdio and admin calls skip missing keys",
+			config: MCPQuotaConfig{SessionCallsPerMinute: 1, PlayerCallsPerMinute: 1},
+			calls:  []call{{"", "p1", ""}, {"s1", "", ""}, {"s1", "", "session call quota exceeded"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := newTestQuotas(t, nil, &tt.config)
+			for i, c := range tt.calls {
+				err := q.allowCall(c.session, c.player)
+				switch {
+				case c.wantErr == "" && err != nil:
+					t.Fatalf("call %d: %v", i, err)
+				case c.wantErr != "" && (err == nil || !strings.Contains(err.Error(), c.wantErr)):
+					t.Fatalf("call %d: error = %v, want %q", i, err, c.wantErr)
+				}
+			}
+		})
+	}
+}
+
+func TestReservePlacements(t *testing.T) {
+	type reservation struct {
+		round   int
+		player  string
+		n       int
+		wantErr bool
+	}
+	tests := []struct {
+		name         string
+		perRound     int
+		reservations []reservation
+	}{
+		{
+			name:     "counts up to the quota",
+			perRound: 5,
+			reservations: []reservation{
+				{1, "p1", 3, false},
+				{1, "p1", 2, false},
+				{1, "p1", 1, true},
+			},
+		},
+		{
+			name:     "refused batches take nothing",
+			perRound: 5,
+			reservations: []reservation{
+				{1, "p1", 4, false},
+				{1, "p1", 2, true},
+				{1, "p1", 1, false},
+			},
+		},
+		{
+			name:     "resets each round",
+			perRound: 2,
+			reservations: []reservation{
+				{1, "p1", 2, false},
+				{1, "p1", 1, true},
+				{2, "p1", 2, false},
+			},
+		},
+		{
+			name:     "players are counted separately",
+			perRound: 2,
+			reservations: []reservation{
+				{1, "p1", 2, false},
+				{1, "p2", 2, false},
+				{1, "p1", 1, true},
+			},
+		},
+		{
+			name:         "disabled",
+			perRound:     0,
+			reservations: []reservation{{1, "p1", 100, false}},
+		},
+		{
+			name:         "nothing to place",
+			perRound:     1,
+			reservations: []reservation{{1, "p1", 1, false}, {1, "p1", 0, false}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manager := &roundManager{}
+			q := newTestQuotas(t, manager, &MCPQuotaConfig{PlacementsPerRound: tt.perRound})
+			for i, r := range tt.reservations {
+				manager.roundID = r.round
+				if err := q.reservePlacements(r.player, r.n); (err != nil) != r.wantErr {
+					t.Fatalf("reservation %d: error = %v, want error %v", i, err, r.wantErr)
+				}
+			}
+		})
+	}
+}
+
+func TestToolPlacements(t *testing.T) {
+	tests := []struct {
+		tool string
+		args map[string]any
+		want int
+	}{
+		{"place_bit", map[string]any{"x": 1, "y": 2}, 1},
+		{"place_bits", map[string]any{"cells": []any{map[string]any{}, map[string]any{}, map[string]any{}}}, 3},
+		{"place_bits", map[string]any{"cells": "not a list"}, 0},
+		{"place_bits", nil, 0},
+		{"get_game_state", nil, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tool, func(t *testing.T) {
+			var request mcp.CallToolRequest
+			request.Params.Name = tt.tool
+			request.Params.Arguments = tt.args
+			if got := toolPlacements(request); got != tt.want {
+				t.Errorf("toolPlacements = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAuditArguments(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  any
+		want any
+	}{
+		{"plain", map[string]any{"x": 1, "team": "red"}, map[string]any{"x": 1, "team": "red"}},
+		{"token", map[string]any{"token": "abc", "x": 1}, map[string]any{"token": "[redacted]", "x": 1}},
+		{"contains secret name", map[string]any{"sessionToken": "abc", "API_KEY": "k"}, map[string]any{"sessionToken": "[redacted]", "API_KEY": "[redacted]"}},
+		{"not an object", "raw", "raw"},
+		{"nil", nil, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := auditArguments(tt.raw); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("auditArguments = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -2,8 +2,12 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"strconv"
+	"time"
 
+	"server/auth"
 	"server/types"
 
 	"github.com/mark3labs/mcp-go/mcp"
@@ -12,33 +16,57 @@ import (
 
 // MCPGameServer wraps the MCP server with game-specific functionality
 type MCPGameServer struct {
-	mcpServer   *server.MCPServer
-	gameManager types.NATSManager
+	mcpServer     *server.MCPServer
+	gameManager   types.NATSManager
+	signer        *auth.Signer
+	subscriptions *ResourceSubscriptions
+	sessions      *MCPSessions
+	quotas        *MCPQuotas
+	adminToken    string // enables admin sessions and tools when set
 }
 
-// NewMCPGameServer creates a new MCP server for the BitSplat game
-func NewMCPGameServer(gameManager types.NATSManager) *MCPGameServer {
-	mcpServer := server.NewMCPServer(
+// NewMCPGameServer creates a new MCP server for the BitSplat game. Sessions
+// act as the player of the session token, issued by signer, they connected with.
+// Every tool call is checked against quotas and recorded in the MCP audit trail.
+func NewMCPGameServer(gameManager types.NATSManager, signer *auth.Signer, quotas *MCPQuotas) *MCPGameServer {
+	gameServer := &MCPGameServer{
+		gameManager: gameManager,
+		signer:      signer,
+		sessions:    NewMCPSessions(),
+		quotas:      quotas,
+	}
+	hooks := &server.Hooks{}
+	gameServer.sessionHooks(hooks)
+
+	gameServer.mcpServer = server.NewMCPServer(
 		"BitSplat Game Server",
 		"1.0.0",
 		server.WithToolCapabilities(true),
 		server.WithResourceCapabilities(true, false),
+		server.WithPromptCapabilities(false),
+		server.WithHooks(hooks),
+		server.WithToolHandlerMiddleware(gameServer.quotaMiddleware),
 		server.WithLogging(),
 		server.WithRecovery(),
 	)
-
-	gameServer := &MCPGameServer{
-		mcpServer:   mcpServer,
-		gameManager: gameManager,
-	}
+	gameServer.subscriptions = NewResourceSubscriptions(gameServer.mcpServer, gameManager)
 
 	// Add game tools
 	gameServer.setupGameTools()
+	gameServer.setupStrategyTools()
+	gameServer.setupTournamentTools()
 	gameServer.setupGameResources()
+	gameServer.setupGamePrompts()
 
 	return gameServer
 }
 
+// WatchResources starts sending resource update notifications to subscribed
+// sessions, at most once per interval, until ctx is done
+func (gs *MCPGameServer) WatchResources(ctx context.Context, interval time.Duration) error {
+	return gs.subscriptions.Run(ctx, interval)
+}
+
 // GetMCPServer returns the underlying MCP server
 func (gs *MCPGameServer) GetMCPServer() *server.MCPServer {
 	return gs.mcpServer
@@ -50,8 +78,7 @@ func (gs *MCPGameServer) setupGameTools() {
 	placeBitTool := mcp.NewTool("place_bit",
 		mcp.WithDescription("Place a bit on the game grid for a specific user"),
 		mcp.WithString("user_id",
-			mcp.Required(),
-			mcp.Description("The ID of the user placing the bit"),
+			mcp.Description("The ID of the user placing the bit; must be the session's player if given"),
 		),
 		mcp.WithNumber("x",
 			mcp.Required(),
@@ -74,8 +101,7 @@ func (gs *MCPGameServer) setupGameTools() {
 	getPlayerTool := mcp.NewTool("get_player_state",
 		mcp.WithDescription("Get the state of a specific player"),
 		mcp.WithString("user_id",
-			mcp.Required(),
-			mcp.Description("The ID of the user to get state for"),
+			mcp.Description("The ID of the user to get state for; must be the session's player if given"),
 		),
 	)
 	gs.mcpServer.AddTool(getPlayerTool, gs.handleGetPlayerState)
@@ -84,8 +110,7 @@ func (gs *MCPGameServer) setupGameTools() {
 	addPlayerTool := mcp.NewTool("add_player",
 		mcp.WithDescription("Add a new player to the game"),
 		mcp.WithString("user_id",
-			mcp.Required(),
-			mcp.Description("The ID of the user to add"),
+			mcp.Description("The ID of the user to add; must be the session's player if given"),
 		),
 	)
 	gs.mcpServer.AddTool(addPlayerTool, gs.handleAddPlayer)
@@ -95,24 +120,67 @@ func (gs *MCPGameServer) setupGameTools() {
 		mcp.WithDescription("Get information about all teams"),
 	)
 	gs.mcpServer.AddTool(getTeamTool, gs.handleGetTeamInfo)
+
+	// Get Heatmap Tool
+	getHeatmapTool := mcp.NewTool("get_heatmap",
+		mcp.WithDescription("Get the contested-cell heatmap for the current round: how often each cell changed hands, or how recently it was captured"),
+		mcp.WithString("mode",
+			mcp.Description("\"changes\" (default) ranks cells by hand changes, \"recent\" by how recently they were captured"),
+			mcp.Enum(HeatmapModeChanges, HeatmapModeRecent),
+		),
+		mcp.WithNumber("limit",
+			mcp.Description("Maximum number of cells to return, hottest first (default 20)"),
+		),
+	)
+	gs.mcpServer.AddTool(getHeatmapTool, gs.handleGetHeatmap)
 }
 
-// setupGameResources configures game-related MCP resources
+// setupGameResources configures game-related MCP resources. They return
+// the same JSON as the HTTP API under /api.
 func (gs *MCPGameServer) setupGameResources() {
 	// Game State Resource
 	gameStateResource := mcp.NewResource("game://state",
 		"Current Game State",
+		mcp.WithResourceDescription("Full game state: round, grid owners and teams with their players"),
 		mcp.WithMIMEType("application/json"),
 	)
 	gs.mcpServer.AddResource(gameStateResource, gs.handleGameStateResource)
+
+	playerTemplate := mcp.NewResourceTemplate("game://player/{id}",
+		"Player",
+		mcp.WithTemplateDescription("A player's team, bits and connection status"),
+		mcp.WithTemplateMIMEType("application/json"),
+	)
+	gs.mcpServer.AddResourceTemplate(playerTemplate, gs.handlePlayerResource)
+
+	teamTemplate := mcp.NewResourceTemplate("game://team/{id}",
+		"Team",
+		mcp.WithTemplateDescription("A team's score, coverage and players"),
+		mcp.WithTemplateMIMEType("application/json"),
+	)
+	gs.mcpServer.AddResourceTemplate(teamTemplate, gs.handleTeamResource)
+
+	gridTemplate := mcp.NewResourceTemplate("game://grid/{x0},{y0}-{x1},{y1}",
+		"Grid Region",
+		mcp.WithTemplateDescription("Cell owners in a rectangle of the grid, corners inclusive, one row per y"),
+		mcp.WithTemplateMIMEType("application/json"),
+	)
+	gs.mcpServer.AddResourceTemplate(gridTemplate, gs.handleGridResource)
+
+	roundTemplate := mcp.NewResourceTemplate("game://rounds/{id}",
+		"Round",
+		mcp.WithTemplateDescription("A round's start and end, winner and final scores"),
+		mcp.WithTemplateMIMEType("application/json"),
+	)
+	gs.mcpServer.AddResourceTemplate(roundTemplate, gs.handleRoundResource)
 }
 
 // Tool Handlers
 
 func (gs *MCPGameServer) handlePlaceBit(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	userID, err := request.RequireString("user_id")
+	userID, err := gs.authenticate(ctx, request)
 	if err != nil {
-		return mcp.NewToolResultError("user_id is required"), nil
+		return mcp.NewToolResultError(err.Error()), nil
 	}
 
 	x, err := request.RequireFloat("x")
@@ -125,11 +193,10 @@ func (gs *MCPGameServer) handlePlaceBit(ctx context.Context, request mcp.CallToo
 		return mcp.NewToolResultError("y coordinate is required"), nil
 	}
 
-	// Ensure player exists
+	// Rejoin the session's player if it was removed from the game
 	if player, _ := gs.gameManager.GetPlayer(userID); player == nil {
-		_, err := gs.gameManager.AddPlayer(userID)
-		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to add player: %v", err)), nil
+		if err := gs.joinPlayer(userID); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
 		}
 	}
 
@@ -152,25 +219,19 @@ func (gs *MCPGameServer) handleGetGameState(ctx context.Context, request mcp.Cal
 		return mcp.NewToolResultError(fmt.Sprintf("Failed to get game state: %v", err)), nil
 	}
 
-	return &mcp.CallToolResult{
-		Content: []mcp.Content{
-			mcp.TextContent{
-				Type: "text",
-				Text: fmt.Sprintf("🎮 Current Game State:\n- Round: %s\n- Time Remaining: %v\n- Teams: %d\n- Grid Size: %dx%d",
-					gameState.RoundState,
-					gameState.RoundTimeRemaining,
-					len(gameState.Teams),
-					types.GridWidth,
-					types.GridHeight),
-			},
-		},
-	}, nil
+	summary := fmt.Sprintf("🎮 Current Game State:\n- Round: %s\n- Time Remaining: %v\n- Teams: %d\n- Grid Size: %dx%d",
+		gameState.RoundState,
+		gameState.RoundTimeRemaining,
+		len(gameState.Teams),
+		types.GridWidth,
+		types.GridHeight)
+	return jsonToolResult(summary, newGameStateJSON(gameState)), nil
 }
 
 func (gs *MCPGameServer) handleGetPlayerState(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	userID, err := request.RequireString("user_id")
+	userID, err := gs.authenticate(ctx, request)
 	if err != nil {
-		return mcp.NewToolResultError("user_id is required"), nil
+		return mcp.NewToolResultError(err.Error()), nil
 	}
 
 	player, team := gs.gameManager.GetPlayer(userID)
@@ -194,9 +255,9 @@ func (gs *MCPGameServer) handleGetPlayerState(ctx context.Context, request mcp.C
 }
 
 func (gs *MCPGameServer) handleAddPlayer(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	userID, err := request.RequireString("user_id")
+	userID, err := gs.authenticate(ctx, request)
 	if err != nil {
-		return mcp.NewToolResultError("user_id is required"), nil
+		return mcp.NewToolResultError(err.Error()), nil
 	}
 
 	player, err := gs.gameManager.AddPlayer(userID)
@@ -240,6 +301,48 @@ func (gs *MCPGameServer) handleGetTeamInfo(ctx context.Context, request mcp.Call
 	}, nil
 }
 
+func (gs *MCPGameServer) handleGetHeatmap(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	mode := request.GetString("mode", HeatmapModeChanges)
+	if mode != HeatmapModeChanges && mode != HeatmapModeRecent {
+		return mcp.NewToolResultError("mode must be changes or recent"), nil
+	}
+	limit := request.GetInt("limit", 20)
+	if limit < 1 {
+		limit = 1
+	}
+
+	snapshot := contestHeatmap.Snapshot(mode)
+	if len(snapshot.Cells) > limit {
+		snapshot.Cells = snapshot.Cells[:limit]
+	}
+
+	summary := fmt.Sprintf("🔥 Heatmap for round %d (%s):\n", snapshot.RoundID, mode)
+	if len(snapshot.Cells) == 0 {
+		summary += "No cells captured yet this round\n"
+	}
+	for _, c := range snapshot.Cells {
+		summary += fmt.Sprintf("- (%d, %d): %d hand changes, %d captures, last %s ago\n",
+			c.X, c.Y, c.Changes, c.Captures, time.Since(c.LastCaptured).Round(time.Second))
+	}
+
+	return jsonToolResult(summary, snapshot), nil
+}
+
+// authenticate returns the player the MCP session acts as. An explicit
+// user_id must name that player.
+func (gs *MCPGameServer) authenticate(ctx context.Context, request mcp.CallToolRequest) (string, error) {
+	playerID, err := gs.sessionPlayer(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	if userID := request.GetString("user_id", ""); userID != "" && userID != playerID {
+		return "", fmt.Errorf("user_id %s is not the player of this session", userID)
+	}
+
+	return playerID, nil
+}
+
 // Resource Handlers
 
 func (gs *MCPGameServer) handleGameStateResource(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
@@ -248,80 +351,96 @@ func (gs *MCPGameServer) handleGameStateResource(ctx context.Context, request mc
 		return nil, fmt.Errorf("failed to get game state: %w", err)
 	}
 
-	serializableState := gs.convertGameStateToSerializable(gameState)
+	return jsonResource(request.Params.URI, newGameStateJSON(gameState))
+}
 
-	return []mcp.ResourceContents{
-		mcp.TextResourceContents{
-			URI:      "game://state",
-			MIMEType: "application/json",
-			Text:     fmt.Sprintf("%+v", serializableState),
-		},
-	}, nil
+func (gs *MCPGameServer) handlePlayerResource(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	playerID := templateArg(request, "id")
+	player, _ := gs.gameManager.GetPlayer(playerID)
+	if player == nil {
+		return nil, fmt.Errorf("player %s not found", playerID)
+	}
+
+	return jsonResource(request.Params.URI, newPlayerJSON(player))
 }
 
-// Helper Methods
+func (gs *MCPGameServer) handleTeamResource(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	gameState, err := gs.gameManager.GetGameState()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get game state: %w", err)
+	}
 
-func (gs *MCPGameServer) convertGameStateToSerializable(gameState *types.GameState) map[string]interface{} {
-	// Convert grid
-	grid := make([][]string, types.GridHeight)
-	for y := 0; y < types.GridHeight; y++ {
-		grid[y] = make([]string, types.GridWidth)
-		for x := 0; x < types.GridWidth; x++ {
-			key := fmt.Sprintf("%d:%d", x, y)
-			if cell, ok := gameState.Grid.Load(key); ok {
-				grid[y][x] = cell.(types.Cell).OwnerID
-			} else {
-				grid[y][x] = "neutral"
-			}
-		}
+	teamID := templateArg(request, "id")
+	team, ok := gameState.Teams[teamID]
+	if !ok {
+		return nil, fmt.Errorf("team %s not found", teamID)
 	}
 
-	// Convert teams
-	teams := make(map[string]interface{})
-	for id, team := range gameState.Teams {
-		players := make([]map[string]interface{}, 0)
-		team.Players.Range(func(key, value interface{}) bool {
-			player := value.(*types.Player)
-			players = append(players, map[string]interface{}{
-				"id":          player.ID,
-				"teamId":      player.TeamID,
-				"color":       player.Color,
-				"bits":        player.Bits,
-				"isConnected": player.IsConnected,
-			})
-			return true
-		})
+	return jsonResource(request.Params.URI, newTeamJSON(team, true))
+}
 
-		teams[id] = map[string]interface{}{
-			"id":            team.ID,
-			"color":         team.Color,
-			"score":         team.Score,
-			"percentage":    team.Percentage,
-			"activePlayers": team.ActivePlayers,
-			"idlePlayers":   team.IdlePlayers,
-			"players":       players,
+func (gs *MCPGameServer) handleGridResource(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	var corners [4]int
+	for i, name := range []string{"x0", "y0", "x1", "y1"} {
+		n, err := strconv.Atoi(templateArg(request, name))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s must be an integer", errInvalidRegion, name)
 		}
+		corners[i] = n
 	}
 
-	var winner map[string]interface{}
-	if gameState.Winner != nil {
-		winner = map[string]interface{}{
-			"id":    gameState.Winner.ID,
-			"color": gameState.Winner.Color,
-			"score": gameState.Winner.Score,
-		}
+	gameState, err := gs.gameManager.GetGameState()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get game state: %w", err)
 	}
 
-	return map[string]interface{}{
-		"grid":               grid,
-		"teams":              teams,
-		"roundState":         string(gameState.RoundState),
-		"roundTimeRemaining": gameState.RoundTimeRemaining.Seconds(),
-		"countdown":          gameState.Countdown.Seconds(),
-		"winner":             winner,
-		"dimensions": map[string]int{
-			"width":  types.GridWidth,
-			"height": types.GridHeight,
+	region, err := newGridRegionJSON(gameState, corners[0], corners[1], corners[2], corners[3])
+	if err != nil {
+		return nil, err
+	}
+	return jsonResource(request.Params.URI, region)
+}
+
+func (gs *MCPGameServer) handleRoundResource(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	roundID, err := strconv.Atoi(templateArg(request, "id"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid round ID %q", templateArg(request, "id"))
+	}
+
+	record, err := gs.gameManager.GetRound(roundID)
+	if err != nil {
+		return nil, err
+	}
+	return jsonResource(request.Params.URI, record)
+}
+
+// Helper Methods
+
+// jsonResource encodes v as the JSON contents of a resource
+func jsonResource(uri string, v interface{}) ([]mcp.ResourceContents, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %s: %w", uri, err)
+	}
+
+	return []mcp.ResourceContents{
+		mcp.TextResourceContents{
+			URI:      uri,
+			MIMEType: "application/json",
+			Text:     string(data),
 		},
+	}, nil
+}
+
+// templateArg returns a variable matched from a resource template URI
+func templateArg(request mcp.ReadResourceRequest, name string) string {
+	switch v := request.Params.Arguments[name].(type) {
+	case []string:
+		if len(v) > 0 {
+			return v[0]
+		}
+	case string:
+		return v
 	}
+	return ""
 }
@@ -0,0 +1,365 @@
+package main
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"server/auth"
+	"server/types"
+
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// MCP clients authenticate once, when they connect, with a player session
+// token (Authorization: Bearer or ?token=). The session then acts as that
+// player; tools no longer take a token and user_id may only name the
+// session's own player. Connecting with ADMIN_TOKEN instead opens an admin
+// session that plays as no one but may use the admin tools.
+
+// mcpClaimsKey is the context key of the claims a connection authenticated with
+type mcpClaimsKey struct{}
+
+func withMCPClaims(ctx context.Context, claims *auth.Claims) context.Context {
+	return context.WithValue(ctx, mcpClaimsKey{}, claims)
+}
+
+func mcpClaimsFromContext(ctx context.Context) *auth.Claims {
+	claims, _ := ctx.Value(mcpClaimsKey{}).(*auth.Claims)
+	return claims
+}
+
+// mcpAdminKey is the context key marking connections made with the admin token
+type mcpAdminKey struct{}
+
+func withMCPAdmin(ctx context.Context) context.Context {
+	return context.WithValue(ctx, mcpAdminKey{}, true)
+}
+
+func mcpAdminFromContext(ctx context.Context) bool {
+	admin, _ := ctx.Value(mcpAdminKey{}).(bool)
+	return admin
+}
+
+// mcpStreamKey is the context key of the function that ends a connection's
+// event stream
+type mcpStreamKey struct{}
+
+func withMCPStream(ctx context.Context, cancel context.CancelFunc) context.Context {
+	return context.WithValue(ctx, mcpStreamKey{}, cancel)
+}
+
+func mcpStreamFromContext(ctx context.Context) context.CancelFunc {
+	cancel, _ := ctx.Value(mcpStreamKey{}).(context.CancelFunc)
+	return cancel
+}
+
+// defaultMCPSessionIdleTimeout ends streamable HTTP sessions without an open
+// stream that made no request for this long
+const defaultMCPSessionIdleTimeout = 10 * time.Minute
+
+// MCPSessions binds MCP session IDs to the players they authenticated as
+type MCPSessions struct {
+	mu       sync.Mutex
+	players  map[string]string             // session ID -> player ID
+	admins   map[string]bool               // admin session IDs
+	streams  map[string]context.CancelFunc // sessions with an open event stream, and how to end it
+	lastSeen map[string]time.Time          // last request of streamable HTTP sessions
+}
+
+// NewMCPSessions creates an empty session registry
+func NewMCPSessions() *MCPSessions {
+	return &MCPSessions{
+		players:  make(map[string]string),
+		admins:   make(map[string]bool),
+		streams:  make(map[string]context.CancelFunc),
+		lastSeen: make(map[string]time.Time),
+	}
+}
+
+// BindAdmin records that a session connected with the admin token.
+// closeStream, if not nil, ends its event stream.
+func (s *MCPSessions) BindAdmin(sessionID string, closeStream context.CancelFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.admins[sessionID] = true
+	s.streams[sessionID] = closeStream
+}
+
+// IsAdmin reports whether a session connected with the admin token
+func (s *MCPSessions) IsAdmin(sessionID string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.admins[sessionID]
+}
+
+// Bind records that a session with an open event stream acts as playerID.
+// closeStream, if not nil, ends the stream.
+func (s *MCPSessions) Bind(sessionID, playerID string, closeStream context.CancelFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.players[sessionID] = playerID
+	s.streams[sessionID] = closeStream
+}
+
+// CloseStream ends a session's event stream. It reports false when the
+// session has no stream it can end.
+func (s *MCPSessions) CloseStream(sessionID string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	closeStream := s.streams[sessionID]
+	if closeStream == nil {
+		return false
+	}
+	closeStream()
+	return true
+}
+
+// CloseStreams ends every open event stream and returns how many it ended
+func (s *MCPSessions) CloseStreams() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	closed := 0
+	for _, closeStream := range s.streams {
+		if closeStream != nil {
+			closeStream()
+			closed++
+		}
+	}
+	return closed
+}
+
+// Touch records a streamable HTTP request of a session acting as playerID.
+// Such sessions need not open a stream, so they end on DELETE or when idle.
+func (s *MCPSessions) Touch(sessionID, playerID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.players[sessionID] = playerID
+	s.lastSeen[sessionID] = time.Now()
+}
+
+// Idle returns the sessions without an open stream whose last request was
+// before cutoff
+func (s *MCPSessions) Idle(cutoff time.Time) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var sessionIDs []string
+	for sessionID, seen := range s.lastSeen {
+		if _, open := s.streams[sessionID]; !open && seen.Before(cutoff) {
+			sessionIDs = append(sessionIDs, sessionID)
+		}
+	}
+	return sessionIDs
+}
+
+// Unbind forgets a session. last reports whether it was the player's only
+// MCP session.
+func (s *MCPSessions) Unbind(sessionID string) (playerID string, last bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.admins, sessionID)
+	delete(s.streams, sessionID)
+	delete(s.lastSeen, sessionID)
+	playerID, ok := s.players[sessionID]
+	if !ok {
+		return "", false
+	}
+	delete(s.players, sessionID)
+	for _, other := range s.players {
+		if other == playerID {
+			return playerID, false
+		}
+	}
+	return playerID, true
+}
+
+// PlayerSessions returns the IDs of the sessions bound to playerID
+func (s *MCPSessions) PlayerSessions(playerID string) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var sessionIDs []string
+	for sessionID, id := range s.players {
+		if id == playerID {
+			sessionIDs = append(sessionIDs, sessionID)
+		}
+	}
+	return sessionIDs
+}
+
+// Player returns the player a session is bound to
+func (s *MCPSessions) Player(sessionID string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	playerID, ok := s.players[sessionID]
+	return playerID, ok
+}
+
+// sessionHooks binds sessions to the player in their connect context and marks
+// the player idle when their last session goes away
+func (gs *MCPGameServer) sessionHooks(hooks *server.Hooks) {
+	hooks.AddOnRegisterSession(func(ctx context.Context, session server.ClientSession) {
+		if mcpAdminFromContext(ctx) {
+			gs.sessions.BindAdmin(session.SessionID(), mcpStreamFromContext(ctx))
+			log.Printf("🛡️ MCP admin session %s connected", session.SessionID())
+			return
+		}
+		claims := mcpClaimsFromContext(ctx)
+		if claims == nil {
+			return
+		}
+		gs.sessions.Bind(session.SessionID(), claims.PlayerID, mcpStreamFromContext(ctx))
+		log.Printf("🤖 MCP session %s connected as player %s", session.SessionID(), claims.PlayerID)
+	})
+
+	hooks.AddOnUnregisterSession(func(ctx context.Context, session server.ClientSession) {
+		gs.endSession(session.SessionID(), "disconnected")
+	})
+}
+
+// endSession forgets a session and its subscriptions, marking the player idle
+// when it was their last MCP session
+func (gs *MCPGameServer) endSession(sessionID, reason string) {
+	gs.subscriptions.RemoveSession(sessionID)
+
+	playerID, last := gs.sessions.Unbind(sessionID)
+	if !last {
+		return
+	}
+	if err := gs.gameManager.SetPlayerIdle(playerID); err != nil {
+		log.Printf("⚠️ Failed to mark player %s idle: %v", playerID, err)
+		return
+	}
+	log.Printf("🔌 MCP session %s %s, player %s marked as idle", sessionID, reason, playerID)
+}
+
+// ExpireSessions ends streamable HTTP sessions that have no open stream and
+// made no request for timeout, until ctx is done
+func (gs *MCPGameServer) ExpireSessions(ctx context.Context, timeout time.Duration) {
+	go func() {
+		ticker := time.NewTicker(timeout / 2)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				for _, sessionID := range gs.sessions.Idle(time.Now().Add(-timeout)) {
+					gs.endSession(sessionID, "timed out")
+				}
+			}
+		}
+	}()
+}
+
+// DisconnectPlayer ends the MCP sessions of a kicked or banned player. Open
+// event streams are closed, which unregisters their sessions; sessions
+// without one are forgotten. Their clients get 403 on their next request.
+func (gs *MCPGameServer) DisconnectPlayer(playerID string) {
+	for _, sessionID := range gs.sessions.PlayerSessions(playerID) {
+		if gs.sessions.CloseStream(sessionID) {
+			log.Printf("👢 Closed MCP stream %s of player %s", sessionID, playerID)
+			continue
+		}
+		gs.endSession(sessionID, "ended")
+		log.Printf("👢 Ended MCP session %s of player %s", sessionID, playerID)
+	}
+}
+
+// CloseStreams ends the event streams of every SSE and streamable HTTP
+// session, which the HTTP server's shutdown would otherwise wait for
+func (gs *MCPGameServer) CloseStreams() {
+	if closed := gs.sessions.CloseStreams(); closed > 0 {
+		log.Printf("🔌 Closed %d MCP streams", closed)
+	}
+}
+
+// sessionPlayer returns the player the current MCP request acts as
+func (gs *MCPGameServer) sessionPlayer(ctx context.Context) (string, error) {
+	if claims := mcpClaimsFromContext(ctx); claims != nil {
+		return claims.PlayerID, nil
+	}
+	if session := server.ClientSessionFromContext(ctx); session != nil {
+		if playerID, ok := gs.sessions.Player(session.SessionID()); ok {
+			return playerID, nil
+		}
+	}
+	return "", fmt.Errorf("MCP session is not authenticated; connect with a player session token")
+}
+
+// sessionAdmin reports whether the current MCP request comes from an admin
+// session
+func (gs *MCPGameServer) sessionAdmin(ctx context.Context) bool {
+	if mcpAdminFromContext(ctx) {
+		return true
+	}
+	session := server.ClientSessionFromContext(ctx)
+	return session != nil && gs.sessions.IsAdmin(session.SessionID())
+}
+
+// joinPlayer adds or reconnects a player, failing for banned players and for
+// players that are not agents during a tournament
+func (gs *MCPGameServer) joinPlayer(playerID string) error {
+	_, err := gs.gameManager.AddPlayer(playerID)
+	if errors.Is(err, types.ErrPlayerBanned) || errors.Is(err, types.ErrPlayerKicked) || errors.Is(err, types.ErrTournamentInProgress) {
+		return err
+	} else if err != nil {
+		return fmt.Errorf("failed to add player: %w", err)
+	}
+	return nil
+}
+
+// RequireSession authenticates MCP connections with a player session token.
+// Opening a stream (GET) joins or reconnects the player; other requests only
+// join players that are not in the game or are idle.
+func (gs *MCPGameServer) RequireSession(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			// Let DisconnectPlayer and shutdown end the event stream this
+			// request opens
+			ctx, closeStream := context.WithCancel(r.Context())
+			defer closeStream()
+			r = r.WithContext(withMCPStream(ctx, closeStream))
+		}
+
+		token := requestSessionToken(r)
+		if token == "" {
+			// SSE clients that cannot set headers pass the token in the URL
+			token = r.URL.Query().Get("token")
+		}
+		if token == "" {
+			http.Error(w, "MCP requires a player session token", http.StatusUnauthorized)
+			return
+		}
+		if gs.adminToken != "" && subtle.ConstantTimeCompare([]byte(token), []byte(gs.adminToken)) == 1 {
+			next.ServeHTTP(w, r.WithContext(withMCPAdmin(r.Context())))
+			return
+		}
+		claims, err := gs.signer.Verify(token)
+		if err != nil {
+			http.Error(w, "Invalid session token", http.StatusUnauthorized)
+			return
+		}
+
+		if player, _ := gs.gameManager.GetPlayer(claims.PlayerID); r.Method == http.MethodGet || player == nil || !player.IsConnected {
+			if err := gs.joinPlayer(claims.PlayerID); errors.Is(err, types.ErrPlayerBanned) {
+				http.Error(w, "You have been banned from this game", http.StatusForbidden)
+				return
+			} else if errors.Is(err, types.ErrPlayerKicked) || errors.Is(err, types.ErrTournamentInProgress) {
+				http.Error(w, err.Error(), http.StatusForbidden)
+				return
+			} else if err != nil {
+				log.Printf("❌ %v", err)
+				http.Error(w, "Failed to add player", http.StatusInternalServerError)
+				return
+			}
+		}
+
+		next.ServeHTTP(w, r.WithContext(withMCPClaims(r.Context(), claims)))
+	})
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"server/auth"
+	"server/types"
+
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// kickManager is a game manager whose players can be kicked
+type kickManager struct {
+	types.NATSManager
+	mu      sync.Mutex
+	players map[string]bool // player ID -> connected
+	kicked  map[string]bool
+}
+
+func newKickManager() *kickManager {
+	return &kickManager{players: make(map[string]bool), kicked: make(map[string]bool)}
+}
+
+func (m *kickManager) AddPlayer(playerID string) (*types.Player, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.kicked[playerID] {
+		return nil, types.ErrPlayerKicked
+	}
+	m.players[playerID] = true
+	return &types.Player{ID: playerID, IsConnected: true}, nil
+}
+
+func (m *kickManager) GetPlayer(playerID string) (*types.Player, *types.Team) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	connected, ok := m.players[playerID]
+	if !ok {
+		return nil, nil
+	}
+	return &types.Player{ID: playerID, IsConnected: connected}, &types.Team{}
+}
+
+func (m *kickManager) SetPlayerIdle(playerID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.players[playerID]; ok {
+		m.players[playerID] = false
+	}
+	return nil
+}
+
+func (m *kickManager) kick(playerID string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.players, playerID)
+	m.kicked[playerID] = true
+}
+
+// openMCPStream opens an event stream with a bearer token
+func openMCPStream(t *testing.T, url, token string) *http.Response {
+	t.Helper()
+	req, _ := http.NewRequest(http.MethodGet, url, nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("GET: %v", err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp
+}
+
+// waitStreamClosed fails unless the server ends the stream soon
+func waitStreamClosed(t *testing.T, resp *http.Response) {
+	t.Helper()
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+		}
+	}()
+	select {
+	case <-closed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("stream is still open")
+	}
+}
+
+func TestDisconnectPlayer(t *testing.T) {
+	signer := auth.NewSigner([]byte("test secret"), time.Hour)
+	token, _, err := signer.Issue("agent")
+	if err != nil {
+		t.Fatalf("Issue: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		handler func(gs *MCPGameServer) http.Handler
+	}{
+		{
+			name: "sse",
+			handler: func(gs *MCPGameServer) http.Handler {
+				return server.NewSSEServer(gs.GetMCPServer()).SSEHandler()
+			},
+		},
+		{
+			name: "streamable http",
+			handler: func(gs *MCPGameServer) http.Handler {
+				return gs.StreamableHTTPHandler()
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manager := newKickManager()
+			gs := NewMCPGameServer(manager, signer, newTestQuotas(t, manager, &MCPQuotaConfig{}))
+			ts := httptest.NewServer(gs.RequireSession(tt.handler(gs)))
+			defer ts.Close()
+
+			resp := openMCPStream(t, ts.URL, token)
+			if resp.StatusCode >= 300 {
+				t.Fatalf("status = %d, want success", resp.StatusCode)
+			}
+			if sessions := gs.sessions.PlayerSessions("agent"); len(sessions) != 1 {
+				t.Fatalf("sessions = %v, want one", sessions)
+			}
+
+			manager.kick("agent")
+			gs.DisconnectPlayer("agent")
+			waitStreamClosed(t, resp)
+
+			deadline := time.Now().Add(5 * time.Second)
+			for len(gs.sessions.PlayerSessions("agent")) > 0 {
+				if time.Now().After(deadline) {
+					t.Fatal("session still bound after its stream closed")
+				}
+				time.Sleep(10 * time.Millisecond)
+			}
+
+			if again := openMCPStream(t, ts.URL, token); again.StatusCode != http.StatusForbidden {
+				t.Errorf("reconnect status = %d, want %d", again.StatusCode, http.StatusForbidden)
+			}
+		})
+	}
+}
+
+func TestCloseStreams(t *testing.T) {
+	signer := auth.NewSigner([]byte("test secret"), time.Hour)
+	token, _, err := signer.Issue("agent")
+	if err != nil {
+		t.Fatalf("Issue: %v", err)
+	}
+
+	manager := newKickManager()
+	gs := NewMCPGameServer(manager, signer, newTestQuotas(t, manager, &MCPQuotaConfig{}))
+	gs.adminToken = "admin secret"
+	ts := httptest.NewServer(gs.RequireSession(gs.StreamableHTTPHandler()))
+	defer ts.Close()
+
+	player := openMCPStream(t, ts.URL, token)
+	admin := openMCPStream(t, ts.URL, gs.adminToken)
+	for _, resp := range []*http.Response{player, admin} {
+		if resp.StatusCode >= 300 {
+			t.Fatalf("status = %d, want success", resp.StatusCode)
+		}
+	}
+
+	gs.CloseStreams()
+	waitStreamClosed(t, player)
+	waitStreamClosed(t, admin)
+}
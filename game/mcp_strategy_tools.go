@@ -0,0 +1,440 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"server/types"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Tools for agents planning moves. Each returns a human-readable summary
+// followed by the same data as JSON.
+
+// Filters accepted by find_cells
+const (
+	CellFilterNeutral = "neutral" // owned by nobody
+	CellFilterEnemy   = "enemy"   // owned by another team
+	CellFilterOwn     = "own"     // owned by the team
+	CellFilterBorder  = "border"  // not the team's, next to a cell that is
+)
+
+// Batch and result size limits
+const (
+	maxPlaceBatch   = types.MaxBits
+	maxFoundCells   = 500
+	maxRecentEvents = 200
+)
+
+// FoundCell is a cell matched by find_cells
+type FoundCell struct {
+	X     int    `json:"x"`
+	Y     int    `json:"y"`
+	Owner string `json:"owner"`
+}
+
+// Placement is the outcome of one cell of a place_bits batch
+type Placement struct {
+	X      int    `json:"x"`
+	Y      int    `json:"y"`
+	Placed bool   `json:"placed"`
+	Error  string `json:"error,omitempty"`
+}
+
+// setupStrategyTools adds get_grid_region, find_cells, get_recent_events,
+// place_bits and get_cooldown_status
+func (gs *MCPGameServer) setupStrategyTools() {
+	regionArgs := []mcp.ToolOption{
+		mcp.WithNumber("x0", mcp.Description("Left column, inclusive (default 0)")),
+		mcp.WithNumber("y0", mcp.Description("Top row, inclusive (default 0)")),
+		mcp.WithNumber("x1", mcp.Description(fmt.Sprintf("Right column, inclusive (default %d)", types.GridWidth-1))),
+		mcp.WithNumber("y1", mcp.Description(fmt.Sprintf("Bottom row, inclusive (default %d)", types.GridHeight-1))),
+	}
+
+	getGridRegionTool := mcp.NewTool("get_grid_region", append([]mcp.ToolOption{
+		mcp.WithDescription("Get the owner of every cell in a rectangle of the grid as a compact matrix, one letter per team and . for neutral"),
+	}, regionArgs...)...)
+	gs.mcpServer.AddTool(getGridRegionTool, gs.handleGetGridRegion)
+
+	findCellsTool := mcp.NewTool("find_cells", append([]mcp.ToolOption{
+		mcp.WithDescription("Find cells by ownership: neutral, enemy-owned, owned by a team, or on the border of a team's territory"),
+		mcp.WithString("filter",
+			mcp.Required(),
+			mcp.Description("neutral: unowned; enemy: owned by another team; own: owned by the team; border: not the team's but next to a cell that is"),
+			mcp.Enum(CellFilterNeutral, CellFilterEnemy, CellFilterOwn, CellFilterBorder),
+		),
+		mcp.WithString("team_id",
+			mcp.Description("Team the enemy, own and border filters are relative to; defaults to the session player's team"),
+		),
+		mcp.WithNumber("limit",
+			mcp.Description(fmt.Sprintf("Maximum number of cells to return (default 50, at most %d)", maxFoundCells)),
+		),
+	}, regionArgs...)...)
+	gs.mcpServer.AddTool(findCellsTool, gs.handleFindCells)
+
+	getRecentEventsTool := mcp.NewTool("get_recent_events",
+		mcp.WithDescription("Get the latest grid changes of a round: placements and grid resets, newest first"),
+		mcp.WithNumber("round_id",
+			mcp.Description("Round to read (default the current round)"),
+		),
+		mcp.WithNumber("limit",
+			mcp.Description(fmt.Sprintf("Maximum number of events (default 20, at most %d)", maxRecentEvents)),
+		),
+	)
+	gs.mcpServer.AddTool(getRecentEventsTool, gs.handleGetRecentEvents)
+
+	placeBitsTool := mcp.NewTool("place_bits",
+		mcp.WithDescription(fmt.Sprintf("Place up to %d bits in order, waiting out the action cooldown between placements", maxPlaceBatch)),
+		mcp.WithString("user_id",
+			mcp.Description("The ID of the user placing the bits; must be the session's player if given"),
+		),
+		mcp.WithArray("cells",
+			mcp.Required(),
+			mcp.Description("Cells to place bits on, in order"),
+			mcp.MinItems(1),
+			mcp.MaxItems(maxPlaceBatch),
+			mcp.Items(map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"x": map[string]any{"type": "integer"},
+					"y": map[string]any{"type": "integer"},
+				},
+				"required": []string{"x", "y"},
+			}),
+		),
+	)
+	gs.mcpServer.AddTool(placeBitsTool, gs.handlePlaceBits)
+
+	getCooldownTool := mcp.NewTool("get_cooldown_status",
+		mcp.WithDescription("Get a player's bits, the time until they can act again and the time until their next bit"),
+		mcp.WithString("user_id",
+			mcp.Description("The ID of the user; must be the session's player if given"),
+		),
+	)
+	gs.mcpServer.AddTool(getCooldownTool, gs.handleGetCooldownStatus)
+}
+
+// jsonToolResult returns a text summary followed by v as JSON
+func jsonToolResult(summary string, v interface{}) *mcp.CallToolResult {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to encode result: %v", err))
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{Type: "text", Text: summary},
+			mcp.TextContent{Type: "text", Text: string(data)},
+		},
+	}
+}
+
+// regionFromRequest reads x0, y0, x1 and y1, defaulting to the whole grid
+func regionFromRequest(request mcp.CallToolRequest) (x0, y0, x1, y1 int, err error) {
+	x0 = request.GetInt("x0", 0)
+	y0 = request.GetInt("y0", 0)
+	x1 = request.GetInt("x1", types.GridWidth-1)
+	y1 = request.GetInt("y1", types.GridHeight-1)
+	return x0, y0, x1, y1, checkRegion(x0, y0, x1, y1)
+}
+
+// teamLetters maps team IDs to the letters get_grid_region draws them with
+func teamLetters() map[string]string {
+	letters := make(map[string]string, len(types.TeamIDs))
+	for _, id := range types.TeamIDs {
+		letters[id] = id[:1]
+	}
+	return letters
+}
+
+// gridMatrix draws a region one letter per cell and row, followed by a legend
+func gridMatrix(region *GridRegionJSON) string {
+	letters := teamLetters()
+	var matrix strings.Builder
+	for _, row := range region.Cells {
+		for _, owner := range row {
+			if letter, ok := letters[owner]; ok {
+				matrix.WriteString(letter)
+			} else {
+				matrix.WriteString(".")
+			}
+		}
+		matrix.WriteString("\n")
+	}
+	matrix.WriteString("Legend: . neutral")
+	for _, id := range types.TeamIDs {
+		fmt.Fprintf(&matrix, ", %s %s", letters[id], id)
+	}
+	return matrix.String()
+}
+
+func (gs *MCPGameServer) handleGetGridRegion(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	x0, y0, x1, y1, err := regionFromRequest(request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	gameState, err := gs.gameManager.GetGameState()
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to get game state: %v", err)), nil
+	}
+	region, err := newGridRegionJSON(gameState, x0, y0, x1, y1)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	summary := fmt.Sprintf("🗺️ Grid (%d,%d)-(%d,%d), one row per y:\n%s", x0, y0, x1, y1, gridMatrix(region))
+	return jsonToolResult(summary, region), nil
+}
+
+func (gs *MCPGameServer) handleFindCells(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	x0, y0, x1, y1, err := regionFromRequest(request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	limit := min(max(request.GetInt("limit", 50), 1), maxFoundCells)
+
+	filter := request.GetString("filter", "")
+	switch filter {
+	case CellFilterNeutral, CellFilterEnemy, CellFilterOwn, CellFilterBorder:
+	default:
+		return mcp.NewToolResultError("filter must be neutral, enemy, own or border"), nil
+	}
+	teamID := request.GetString("team_id", "")
+	if filter != CellFilterNeutral && teamID == "" {
+		userID, err := gs.authenticate(ctx, request)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("team_id is required for the %s filter: %v", filter, err)), nil
+		}
+		_, team := gs.gameManager.GetPlayer(userID)
+		if team == nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Player %s not found", userID)), nil
+		}
+		teamID = team.ID
+	}
+
+	gameState, err := gs.gameManager.GetGameState()
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to get game state: %v", err)), nil
+	}
+	if teamID != "" {
+		if _, ok := gameState.Teams[teamID]; !ok {
+			return mcp.NewToolResultError(fmt.Sprintf("Team %s not found", teamID)), nil
+		}
+	}
+
+	owners := gridOwners(gridCells(gameState), 0, 0, types.GridWidth-1, types.GridHeight-1)
+	ownedByTeam := func(x, y int) bool {
+		return x >= 0 && y >= 0 && x < types.GridWidth && y < types.GridHeight && owners[y][x] == teamID
+	}
+
+	var match func(x, y int, owner string) bool
+	switch filter {
+	case CellFilterNeutral:
+		match = func(x, y int, owner string) bool { return owner == types.NeutralOwnerID }
+	case CellFilterEnemy:
+		match = func(x, y int, owner string) bool { return owner != types.NeutralOwnerID && owner != teamID }
+	case CellFilterOwn:
+		match = func(x, y int, owner string) bool { return owner == teamID }
+	case CellFilterBorder:
+		match = func(x, y int, owner string) bool {
+			return owner != teamID &&
+				(ownedByTeam(x-1, y) || ownedByTeam(x+1, y) || ownedByTeam(x, y-1) || ownedByTeam(x, y+1))
+		}
+	}
+
+	found := make([]FoundCell, 0)
+	total := 0
+	for y := y0; y <= y1; y++ {
+		for x := x0; x <= x1; x++ {
+			if !match(x, y, owners[y][x]) {
+				continue
+			}
+			total++
+			if len(found) < limit {
+				found = append(found, FoundCell{X: x, Y: y, Owner: owners[y][x]})
+			}
+		}
+	}
+
+	summary := fmt.Sprintf("🔎 %d %s cells in (%d,%d)-(%d,%d)", total, filter, x0, y0, x1, y1)
+	if teamID != "" {
+		summary += fmt.Sprintf(" for team %s", teamID)
+	}
+	if total > len(found) {
+		summary += fmt.Sprintf(", showing the first %d", len(found))
+	}
+	for i, c := range found {
+		if i == 10 {
+			summary += "\n- …"
+			break
+		}
+		summary += fmt.Sprintf("\n- (%d, %d) %s", c.X, c.Y, c.Owner)
+	}
+
+	return jsonToolResult(summary, map[string]interface{}{
+		"filter": filter,
+		"teamId": teamID,
+		"total":  total,
+		"cells":  found,
+	}), nil
+}
+
+func (gs *MCPGameServer) handleGetRecentEvents(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	limit := min(max(request.GetInt("limit", 20), 1), maxRecentEvents)
+
+	roundID := request.GetInt("round_id", 0)
+	if roundID == 0 {
+		gameState, err := gs.gameManager.GetGameState()
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to get game state: %v", err)), nil
+		}
+		roundID = gameState.RoundID
+	}
+	if roundID == 0 {
+		return mcp.NewToolResultError("No round has started yet"), nil
+	}
+
+	// Keep the last limit events of the replay, newest first
+	events := make([]*types.RoundEvent, 0, limit)
+	err := gs.gameManager.ReplayRound(ctx, roundID, func(event *types.RoundEvent) error {
+		if len(events) == limit {
+			events = events[1:]
+		}
+		events = append(events, event)
+		return nil
+	})
+	if errors.Is(err, types.ErrRoundNotFound) {
+		return mcp.NewToolResultError(fmt.Sprintf("Round %d not found", roundID)), nil
+	} else if errors.Is(err, types.ErrRoundEventsExpired) {
+		return mcp.NewToolResultError(fmt.Sprintf("Events of round %d have expired", roundID)), nil
+	} else if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to read round events: %v", err)), nil
+	}
+	for i, j := 0, len(events)-1; i < j; i, j = i+1, j-1 {
+		events[i], events[j] = events[j], events[i]
+	}
+
+	summary := fmt.Sprintf("📜 Latest %d events of round %d:", len(events), roundID)
+	if len(events) == 0 {
+		summary = fmt.Sprintf("📜 No grid changes in round %d yet", roundID)
+	}
+	for _, e := range events {
+		ago := time.Since(e.At).Round(time.Second)
+		switch e.Kind {
+		case types.RoundEventBitPlaced:
+			from := e.OldOwner
+			if from == "" {
+				from = types.NeutralOwnerID
+			}
+			summary += fmt.Sprintf("\n- %s ago: %s (%s) took (%d, %d) from %s", ago, e.PlayerID, e.TeamID, e.X, e.Y, from)
+		case types.RoundEventGridReset:
+			summary += fmt.Sprintf("\n- %s ago: grid reset", ago)
+		}
+	}
+
+	return jsonToolResult(summary, map[string]interface{}{
+		"roundId": roundID,
+		"events":  events,
+	}), nil
+}
+
+func (gs *MCPGameServer) handlePlaceBits(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	userID, err := gs.authenticate(ctx, request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	var args struct {
+		Cells []struct {
+			X int `json:"x"`
+			Y int `json:"y"`
+		} `json:"cells"`
+	}
+	if err := request.BindArguments(&args); err != nil || len(args.Cells) == 0 {
+		return mcp.NewToolResultError("cells must be a non-empty list of {x, y}"), nil
+	}
+	if len(args.Cells) > maxPlaceBatch {
+		return mcp.NewToolResultError(fmt.Sprintf("At most %d cells per batch", maxPlaceBatch)), nil
+	}
+
+	if player, _ := gs.gameManager.GetPlayer(userID); player == nil {
+		if err := gs.joinPlayer(userID); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+	}
+
+	results := make([]Placement, 0, len(args.Cells))
+	placed := 0
+	for _, cell := range args.Cells {
+		result := Placement{X: cell.X, Y: cell.Y}
+		if err := checkRegion(cell.X, cell.Y, cell.X, cell.Y); err != nil {
+			result.Error = "outside the grid"
+			results = append(results, result)
+			continue
+		}
+
+		// Wait out the cooldown left by the previous placement
+		if status, err := gs.gameManager.GetCooldownStatus(userID); err == nil && status.NextActionIn > 0 {
+			select {
+			case <-ctx.Done():
+				return mcp.NewToolResultError("Batch cancelled"), nil
+			case <-time.After(status.NextActionIn):
+			}
+		}
+
+		ok, err := gs.gameManager.PlaceBit(userID, cell.X, cell.Y)
+		if err != nil {
+			result.Error = err.Error()
+		} else {
+			result.Placed = ok
+		}
+		if result.Placed {
+			placed++
+		}
+		results = append(results, result)
+	}
+
+	summary := fmt.Sprintf("🎯 Placed %d of %d bits for user %s", placed, len(results), userID)
+	for _, r := range results {
+		if r.Error != "" {
+			summary += fmt.Sprintf("\n- (%d, %d): %s", r.X, r.Y, r.Error)
+		}
+	}
+
+	return jsonToolResult(summary, map[string]interface{}{
+		"placed":  placed,
+		"results": results,
+	}), nil
+}
+
+func (gs *MCPGameServer) handleGetCooldownStatus(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	userID, err := gs.authenticate(ctx, request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	status, err := gs.gameManager.GetCooldownStatus(userID)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	cooldown := newCooldownJSON(status)
+
+	summary := fmt.Sprintf("⏱️ Player %s: %d/%d bits", cooldown.PlayerID, cooldown.Bits, cooldown.MaxBits)
+	if cooldown.CanPlace {
+		summary += ", ready to place"
+	} else if cooldown.NextActionIn > 0 {
+		summary += fmt.Sprintf(", next action in %.1fs", cooldown.NextActionIn)
+	} else {
+		summary += ", cannot place right now"
+	}
+	if cooldown.NextBitIn >= 0 {
+		summary += fmt.Sprintf(", next bit in %.1fs", cooldown.NextBitIn)
+	}
+
+	return jsonToolResult(summary, cooldown), nil
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"testing"
+
+	"server/types"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// stateManager is a game manager that only serves a fixed game state
+type stateManager struct {
+	types.NATSManager
+	state *types.GameState
+}
+
+func (m *stateManager) GetGameState() (*types.GameState, error) {
+	return m.state, nil
+}
+
+// newGridState builds a game state with the teams of owned, e.g.
+// {"1:0": "Glitchbyte"}; other cells are neutral
+func newGridState(owned map[string]string) *types.GameState {
+	state := &types.GameState{Grid: &sync.Map{}, Teams: make(map[string]*types.Team)}
+	for _, id := range types.TeamIDs {
+		state.Teams[id] = &types.Team{ID: id}
+	}
+	for key, teamID := range owned {
+		state.Grid.Store(key, types.Cell{OwnerID: teamID})
+	}
+	return state
+}
+
+func TestFindCells(t *testing.T) {
+	// Row 0: N G . .
+	// Row 1: . G N .
+	gs := &MCPGameServer{gameManager: &stateManager{state: newGridState(map[string]string{
+		"0:0": "Nullwave", "1:0": "Glitchbyte",
+		"1:1": "Glitchbyte", "2:1": "Nullwave",
+	})}}
+	region := map[string]any{"x0": 0, "y0": 0, "x1": 3, "y1": 1}
+
+	tests := []struct {
+		name    string
+		args    map[string]any
+		want    []string // x:y of the cells found
+		total   int
+		wantErr bool
+	}{
+		{"neutral", map[string]any{"filter": "neutral"}, []string{"2:0", "3:0", "0:1", "3:1"}, 4, false},
+		{"own", map[string]any{"filter": "own", "team_id": "Glitchbyte"}, []string{"1:0", "1:1"}, 2, false},
+		{"enemy", map[string]any{"filter": "enemy", "team_id": "Glitchbyte"}, []string{"0:0", "2:1"}, 2, false},
+		{"border", map[string]any{"filter": "border", "team_id": "Glitchbyte"}, []string{"0:0", "2:0", "0:1", "2:1"}, 4, false},
+		{"border of the other team", map[string]any{"filter": "border", "team_id": "Nullwave"}, []string{"1:0", "2:0", "0:1", "1:1", "3:1"}, 5, false},
+		{"limit keeps the total", map[string]any{"filter": "neutral", "limit": 2}, []string{"2:0", "3:0"}, 4, false},
+		{"unknown filter", map[string]any{"filter": "empty"}, nil, 0, true},
+		{"unknown team", map[string]any{"filter": "own", "team_id": "Nobody"}, nil, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var request mcp.CallToolRequest
+			request.Params.Name = "find_cells"
+			args := map[string]any{}
+			for k, v := range region {
+				args[k] = v
+			}
+			for k, v := range tt.args {
+				args[k] = v
+			}
+			request.Params.Arguments = args
+
+			result, err := gs.handleFindCells(context.Background(), request)
+			if err != nil {
+				t.Fatalf("handleFindCells: %v", err)
+			}
+			if result.IsError != tt.wantErr {
+				t.Fatalf("IsError = %v, want %v: %v", result.IsError, tt.wantErr, result.Content)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			var found struct {
+				Total int         `json:"total"`
+				Cells []FoundCell `json:"cells"`
+			}
+			if err := json.Unmarshal([]byte(result.Content[1].(mcp.TextContent).Text), &found); err != nil {
+				t.Fatalf("decode result: %v", err)
+			}
+			var got []string
+			for _, c := range found.Cells {
+				got = append(got, fmt.Sprintf("%d:%d", c.X, c.Y))
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) || found.Total != tt.total {
+				t.Errorf("found %v of %d, want %v of %d", got, found.Total, tt.want, tt.total)
+			}
+		})
+	}
+}
@@ -0,0 +1,392 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"reflect"
+	"regexp"
+	"strconv"
+	"sync"
+	"time"
+
+	"server/types"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// defaultMCPNotifyInterval is the minimum time between two
+// notifications/resources/updated for the same resource
+const defaultMCPNotifyInterval = time.Second
+
+// mcpNotifyIntervalFromEnv reads MCP_NOTIFY_INTERVAL, e.g. "500ms"
+func mcpNotifyIntervalFromEnv() time.Duration {
+	if v := os.Getenv("MCP_NOTIFY_INTERVAL"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d > 0 {
+			return d
+		}
+		log.Printf("⚠️ Invalid MCP_NOTIFY_INTERVAL %q, using %s", v, defaultMCPNotifyInterval)
+	}
+	return defaultMCPNotifyInterval
+}
+
+// Subscribable resource URIs
+var (
+	playerURIPattern = regexp.MustCompile(`^game://player/([^/]+)$`)
+	teamURIPattern   = regexp.MustCompile(`^game://team/([^/]+)$`)
+	gridURIPattern   = regexp.MustCompile(`^game://grid/(\d+),(\d+)-(\d+),(\d+)$`)
+	roundURIPattern  = regexp.MustCompile(`^game://rounds/(\d+)$`)
+)
+
+// resourceRef is a parsed subscribable resource URI
+type resourceRef struct {
+	uri            string
+	kind           string // "state", "player", "team", "grid" or "round"
+	id             string
+	x0, y0, x1, y1 int
+}
+
+func parseResourceURI(uri string) (*resourceRef, error) {
+	ref := &resourceRef{uri: uri}
+	switch {
+	case uri == "game://state":
+		ref.kind = "state"
+	case playerURIPattern.MatchString(uri):
+		ref.kind, ref.id = "player", playerURIPattern.FindStringSubmatch(uri)[1]
+	case teamURIPattern.MatchString(uri):
+		ref.kind, ref.id = "team", teamURIPattern.FindStringSubmatch(uri)[1]
+	case roundURIPattern.MatchString(uri):
+		ref.kind, ref.id = "round", roundURIPattern.FindStringSubmatch(uri)[1]
+	case gridURIPattern.MatchString(uri):
+		m := gridURIPattern.FindStringSubmatch(uri)
+		ref.kind = "grid"
+		ref.x0, _ = strconv.Atoi(m[1])
+		ref.y0, _ = strconv.Atoi(m[2])
+		ref.x1, _ = strconv.Atoi(m[3])
+		ref.y1, _ = strconv.Atoi(m[4])
+		if ref.x0 > ref.x1 || ref.y0 > ref.y1 || ref.x1 >= types.GridWidth || ref.y1 >= types.GridHeight {
+			return nil, fmt.Errorf("%w: %s", errInvalidRegion, uri)
+		}
+	default:
+		return nil, fmt.Errorf("unknown resource %s", uri)
+	}
+	return ref, nil
+}
+
+// watchedState is what subscribers were last notified about
+type watchedState struct {
+	revision   uint64
+	cells      map[string]types.Cell
+	teams      map[string]TeamJSON
+	players    map[string]PlayerJSON
+	roundID    int
+	roundState types.RoundState
+}
+
+// ResourceSubscriptions tracks resources/subscribe requests per MCP session
+// and sends notifications/resources/updated when a subscribed resource
+// changes. Changes are collected from the game state KV watcher and flushed
+// at most once per interval.
+type ResourceSubscriptions struct {
+	mcpServer *server.MCPServer
+	manager   types.NATSManager
+
+	mu       sync.Mutex
+	sessions map[string]map[string]*resourceRef // session ID -> URI -> ref
+	latest   *types.GameState                   // newest state not yet flushed
+	revision uint64
+	last     *watchedState
+}
+
+// NewResourceSubscriptions creates an empty subscription registry
+func NewResourceSubscriptions(mcpServer *server.MCPServer, manager types.NATSManager) *ResourceSubscriptions {
+	return &ResourceSubscriptions{
+		mcpServer: mcpServer,
+		manager:   manager,
+		sessions:  make(map[string]map[string]*resourceRef),
+	}
+}
+
+// Subscribe registers uri for a session
+func (rs *ResourceSubscriptions) Subscribe(sessionID, uri string) error {
+	ref, err := parseResourceURI(uri)
+	if err != nil {
+		return err
+	}
+
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	if rs.sessions[sessionID] == nil {
+		rs.sessions[sessionID] = make(map[string]*resourceRef)
+	}
+	rs.sessions[sessionID][uri] = ref
+	return nil
+}
+
+// Unsubscribe removes uri for a session
+func (rs *ResourceSubscriptions) Unsubscribe(sessionID, uri string) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	delete(rs.sessions[sessionID], uri)
+	if len(rs.sessions[sessionID]) == 0 {
+		delete(rs.sessions, sessionID)
+	}
+}
+
+// RemoveSession drops every subscription of a closed session
+func (rs *ResourceSubscriptions) RemoveSession(sessionID string) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	delete(rs.sessions, sessionID)
+}
+
+// Run follows game state from WatchGameState and flushes notifications every
+// interval until ctx is done
+func (rs *ResourceSubscriptions) Run(ctx context.Context, interval time.Duration) error {
+	watcher, err := rs.manager.WatchGameState()
+	if err != nil {
+		return fmt.Errorf("failed to watch game state: %w", err)
+	}
+
+	go func() {
+		defer watcher.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case entry, ok := <-watcher.Updates():
+				if !ok {
+					return
+				}
+				if entry == nil {
+					continue
+				}
+				var snapshot types.GameStateSnapshot
+				if err := json.Unmarshal(entry.Value(), &snapshot); err != nil {
+					log.Printf("❌ Failed to unmarshal game state: %v", err)
+					continue
+				}
+				rs.mu.Lock()
+				rs.latest = convertSnapshotToGameState(&snapshot)
+				rs.revision = entry.Revision()
+				rs.mu.Unlock()
+			}
+		}
+	}()
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				rs.flush()
+			}
+		}
+	}()
+
+	log.Printf("🔔 MCP resource notifications every %s", interval)
+	return nil
+}
+
+// flush compares the newest state with the last notified one and notifies
+// every session subscribed to something that changed
+func (rs *ResourceSubscriptions) flush() {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	if rs.latest == nil {
+		return
+	}
+	gameState := rs.latest
+	rs.latest = nil
+
+	next := &watchedState{
+		revision:   rs.revision,
+		cells:      gridCells(gameState),
+		teams:      make(map[string]TeamJSON, len(gameState.Teams)),
+		players:    make(map[string]PlayerJSON),
+		roundID:    gameState.RoundID,
+		roundState: gameState.RoundState,
+	}
+	for id, team := range gameState.Teams {
+		next.teams[id] = *newTeamJSON(team, false)
+	}
+	for _, refs := range rs.sessions {
+		for _, ref := range refs {
+			if ref.kind == "player" {
+				if player, _ := rs.manager.GetPlayer(ref.id); player != nil {
+					next.players[ref.id] = newPlayerJSON(player)
+				}
+			}
+		}
+	}
+
+	prev := rs.last
+	rs.last = next
+	if prev == nil {
+		return
+	}
+
+	for sessionID, refs := range rs.sessions {
+		for uri, ref := range refs {
+			if !ref.changed(prev, next) {
+				continue
+			}
+			err := rs.mcpServer.SendNotificationToSpecificClient(sessionID, "notifications/resources/updated", map[string]any{
+				"uri": uri,
+			})
+			if errors.Is(err, server.ErrSessionNotFound) {
+				// Streamable HTTP sessions only receive notifications while
+				// their GET stream is open
+				continue
+			}
+			if err != nil {
+				log.Printf("⚠️ Failed to notify MCP session %s about %s: %v", sessionID, uri, err)
+			}
+		}
+	}
+}
+
+// changed reports whether the resource differs between two states
+func (ref *resourceRef) changed(prev, next *watchedState) bool {
+	switch ref.kind {
+	case "state":
+		return prev.revision != next.revision
+	case "player":
+		return prev.players[ref.id] != next.players[ref.id]
+	case "team":
+		return !reflect.DeepEqual(prev.teams[ref.id], next.teams[ref.id])
+	case "grid":
+		for y := ref.y0; y <= ref.y1; y++ {
+			for x := ref.x0; x <= ref.x1; x++ {
+				key := fmt.Sprintf("%d:%d", x, y)
+				if prev.cells[key] != next.cells[key] {
+					return true
+				}
+			}
+		}
+		return false
+	case "round":
+		if prev.roundID == next.roundID && prev.roundState == next.roundState {
+			return false
+		}
+		id := strconv.Itoa(prev.roundID)
+		return ref.id == id || ref.id == strconv.Itoa(next.roundID)
+	}
+	return false
+}
+
+// subscriptionRequest is the part of a JSON-RPC message needed to answer
+// resources/subscribe and resources/unsubscribe
+type subscriptionRequest struct {
+	ID     mcp.RequestId `json:"id"`
+	Method string        `json:"method"`
+	Params struct {
+		URI string `json:"uri"`
+	} `json:"params"`
+}
+
+// handleMessage answers resources/subscribe and resources/unsubscribe, which
+// the MCP server library does not handle. ok is false for any other message,
+// which should be passed on to the library.
+func (rs *ResourceSubscriptions) handleMessage(sessionID string, message []byte) (response mcp.JSONRPCMessage, ok bool) {
+	var request subscriptionRequest
+	if err := json.Unmarshal(message, &request); err != nil {
+		return nil, false
+	}
+
+	switch request.Method {
+	case "resources/subscribe":
+		if err := rs.Subscribe(sessionID, request.Params.URI); err != nil {
+			return mcp.NewJSONRPCError(request.ID, mcp.INVALID_PARAMS, err.Error(), nil), true
+		}
+		log.Printf("🔔 MCP session %s subscribed to %s", sessionID, request.Params.URI)
+	case "resources/unsubscribe":
+		rs.Unsubscribe(sessionID, request.Params.URI)
+	default:
+		return nil, false
+	}
+	return mcp.JSONRPCResponse{
+		JSONRPC: mcp.JSONRPC_VERSION,
+		ID:      request.ID,
+		Result:  mcp.EmptyResult{},
+	}, true
+}
+
+// readMessage reads a request body and puts it back for the next handler
+func readMessage(r *http.Request) ([]byte, error) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
+
+// SSEMessageHandler wraps the SSE transport's message endpoint. Responses to
+// subscription requests go out on the session's event stream through send.
+func (rs *ResourceSubscriptions) SSEMessageHandler(next http.Handler, send func(sessionID string, event any) error) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			next.ServeHTTP(w, r)
+			return
+		}
+		body, err := readMessage(r)
+		if err != nil {
+			http.Error(w, "Failed to read request", http.StatusBadRequest)
+			return
+		}
+
+		sessionID := r.URL.Query().Get("sessionId")
+		response, ok := rs.handleMessage(sessionID, body)
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if err := send(sessionID, response); err != nil {
+			rs.RemoveSession(sessionID)
+			http.Error(w, "Invalid session ID", http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	})
+}
+
+// StreamableHandler wraps the streamable HTTP transport, answering
+// subscription requests in the response body. The session is the one from the
+// Mcp-Session-Id header; notifications reach it while it has a GET stream open.
+func (rs *ResourceSubscriptions) StreamableHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			next.ServeHTTP(w, r)
+			return
+		}
+		body, err := readMessage(r)
+		if err != nil {
+			http.Error(w, "Failed to read request", http.StatusBadRequest)
+			return
+		}
+
+		sessionID := r.Header.Get("Mcp-Session-Id")
+		if sessionID == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		response, ok := rs.handleMessage(sessionID, body)
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+		writeAPIJSON(w, response)
+	})
+}
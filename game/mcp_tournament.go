@@ -0,0 +1,194 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"server/types"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// setupTournamentTools adds the tournament standings tool every session can use
+func (gs *MCPGameServer) setupTournamentTools() {
+	getTournamentTool := mcp.NewTool("get_tournament",
+		mcp.WithDescription("Get the agent tournament: whether it is running, rounds played, agents ranked by rating and recent match results"),
+	)
+	gs.mcpServer.AddTool(getTournamentTool, gs.handleGetTournament)
+}
+
+// EnableAdminTools lets sessions that connect with token use the tournament
+// admin tools. Without a token there are no admin sessions.
+func (gs *MCPGameServer) EnableAdminTools(token string) {
+	if token == "" {
+		return
+	}
+	gs.adminToken = token
+
+	registerTool := mcp.NewTool("tournament_register_agent",
+		mcp.WithDescription("Admin: register an agent for tournaments, identified by exactly one of player_id, session_id (a connected MCP session) or token (a player session token). Registering again renames or moves the agent."),
+		mcp.WithString("player_id",
+			mcp.Description("Player ID of the agent"),
+		),
+		mcp.WithString("session_id",
+			mcp.Description("MCP session ID the agent is connected with"),
+		),
+		mcp.WithString("token",
+			mcp.Description("Player session token the agent connects with"),
+		),
+		mcp.WithString("name",
+			mcp.Description("Display name (defaults to the player ID)"),
+		),
+		mcp.WithString("team_id",
+			mcp.Description("Team the agent always plays for (defaults to the team with the fewest agents)"),
+			mcp.Enum(types.TeamIDs...),
+		),
+	)
+	gs.mcpServer.AddTool(registerTool, gs.handleRegisterAgent)
+
+	removeTool := mcp.NewTool("tournament_remove_agent",
+		mcp.WithDescription("Admin: unregister a tournament agent"),
+		mcp.WithString("player_id",
+			mcp.Required(),
+			mcp.Description("Player ID of the agent"),
+		),
+	)
+	gs.mcpServer.AddTool(removeTool, gs.handleRemoveAgent)
+
+	startTool := mcp.NewTool("tournament_start",
+		mcp.WithDescription("Admin: start a tournament. Non-agent players leave the game, agents move to their teams and only agents may join until it ends."),
+		mcp.WithNumber("rounds",
+			mcp.Description("Rounds to play before the tournament ends by itself (0 or omitted: until stopped)"),
+			mcp.Min(0),
+		),
+	)
+	gs.mcpServer.AddTool(startTool, gs.handleStartTournament)
+
+	stopTool := mcp.NewTool("tournament_stop",
+		mcp.WithDescription("Admin: stop the running tournament and let everyone join again"),
+	)
+	gs.mcpServer.AddTool(stopTool, gs.handleStopTournament)
+
+	log.Printf("🛡️ MCP admin tools enabled for sessions connecting with the admin token")
+}
+
+func (gs *MCPGameServer) handleGetTournament(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	tournament, err := gs.gameManager.GetTournament()
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to get tournament: %v", err)), nil
+	}
+	return jsonToolResult(tournamentSummary(tournament), tournament), nil
+}
+
+func (gs *MCPGameServer) handleRegisterAgent(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if !gs.sessionAdmin(ctx) {
+		return mcp.NewToolResultError("tournament_register_agent requires an admin session"), nil
+	}
+
+	playerID, err := gs.agentPlayerID(
+		request.GetString("player_id", ""),
+		request.GetString("session_id", ""),
+		request.GetString("token", ""),
+	)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	agent, err := gs.gameManager.RegisterAgent(playerID, request.GetString("name", ""), request.GetString("team_id", ""))
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to register agent: %v", err)), nil
+	}
+	return jsonToolResult(fmt.Sprintf("🤖 Registered agent %s (%s) on team %s, rating %.0f",
+		agent.Name, agent.PlayerID, agent.TeamID, agent.Rating), agent), nil
+}
+
+func (gs *MCPGameServer) handleRemoveAgent(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if !gs.sessionAdmin(ctx) {
+		return mcp.NewToolResultError("tournament_remove_agent requires an admin session"), nil
+	}
+
+	playerID, err := request.RequireString("player_id")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if err := gs.gameManager.RemoveAgent(playerID); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to remove agent: %v", err)), nil
+	}
+	return mcp.NewToolResultText(fmt.Sprintf("🤖 Removed agent %s", playerID)), nil
+}
+
+func (gs *MCPGameServer) handleStartTournament(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if !gs.sessionAdmin(ctx) {
+		return mcp.NewToolResultError("tournament_start requires an admin session"), nil
+	}
+
+	rounds := request.GetInt("rounds", 0)
+	if err := gs.gameManager.StartTournament(rounds); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to start tournament: %v", err)), nil
+	}
+	if rounds == 0 {
+		return mcp.NewToolResultText("🏟️ Tournament started; it runs until stopped"), nil
+	}
+	return mcp.NewToolResultText(fmt.Sprintf("🏟️ Tournament started for %d rounds", rounds)), nil
+}
+
+func (gs *MCPGameServer) handleStopTournament(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if !gs.sessionAdmin(ctx) {
+		return mcp.NewToolResultError("tournament_stop requires an admin session"), nil
+	}
+
+	if err := gs.gameManager.StopTournament(); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to stop tournament: %v", err)), nil
+	}
+	return mcp.NewToolResultText("🏟️ Tournament stopped"), nil
+}
+
+// agentPlayerID resolves the player an agent plays as from exactly one of a
+// player ID, a connected MCP session ID or a player session token
+func (gs *MCPGameServer) agentPlayerID(playerID, sessionID, token string) (string, error) {
+	given := 0
+	for _, v := range []string{playerID, sessionID, token} {
+		if v != "" {
+			given++
+		}
+	}
+	if given != 1 {
+		return "", fmt.Errorf("give exactly one of player_id, session_id or token")
+	}
+
+	switch {
+	case sessionID != "":
+		id, ok := gs.sessions.Player(sessionID)
+		if !ok {
+			return "", fmt.Errorf("no player session %s is connected", sessionID)
+		}
+		return id, nil
+	case token != "":
+		claims, err := gs.signer.Verify(token)
+		if err != nil {
+			return "", fmt.Errorf("invalid session token")
+		}
+		return claims.PlayerID, nil
+	}
+	return playerID, nil
+}
+
+// tournamentSummary describes the tournament state and the top of the table
+func tournamentSummary(t *types.Tournament) string {
+	var b strings.Builder
+	switch {
+	case t.Active && t.Rounds > 0:
+		fmt.Fprintf(&b, "🏟️ Tournament running: round %d of %d\n", t.RoundsPlayed+1, t.Rounds)
+	case t.Active:
+		fmt.Fprintf(&b, "🏟️ Tournament running: %d rounds played, no round limit\n", t.RoundsPlayed)
+	default:
+		fmt.Fprintf(&b, "🏟️ No tournament running (%d agents registered)\n", len(t.Agents))
+	}
+	for i, agent := range t.Agents {
+		fmt.Fprintf(&b, "%d. %s (%s, %s): %.0f, %d wins in %d matches\n",
+			i+1, agent.Name, agent.PlayerID, agent.TeamID, agent.Rating, agent.Wins, agent.Matches)
+	}
+	return b.String()
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+
+	"server/auth"
+
+	"github.com/google/uuid"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// The MCP server is reachable over three transports: SSE (/mcp/sse and
+// /mcp/message), streamable HTTP (/mcp/stream) and, with -stdio, the
+// process's stdin and stdout.
+
+// stdioSessionID is the session ID mcp-go gives its single stdio client
+const stdioSessionID = "stdio"
+
+// publicURLFromEnv reads PUBLIC_URL, the externally reachable base URL of the
+// server, e.g. "https://bitsplat.example.com". Empty when unset.
+func publicURLFromEnv() string {
+	return strings.TrimSuffix(os.Getenv("PUBLIC_URL"), "/")
+}
+
+// requestBaseURL returns PUBLIC_URL, or the scheme and host the request came in on
+func requestBaseURL(r *http.Request, publicURL string) string {
+	if publicURL != "" {
+		return publicURL
+	}
+	scheme := "http"
+	if r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+	return fmt.Sprintf("%s://%s", scheme, r.Host)
+}
+
+// stdioClaims returns the identity a stdio session plays as: the player of
+// MCP_TOKEN, or a fresh player when it is unset
+func stdioClaims(signer *auth.Signer) (*auth.Claims, error) {
+	if token := os.Getenv("MCP_TOKEN"); token != "" {
+		claims, err := signer.Verify(token)
+		if err != nil {
+			return nil, fmt.Errorf("invalid MCP_TOKEN: %w", err)
+		}
+		return claims, nil
+	}
+
+	_, claims, err := signer.Issue(uuid.New().String()[:8])
+	if err != nil {
+		return nil, fmt.Errorf("failed to issue stdio player identity: %w", err)
+	}
+	log.Printf("🆔 MCP_TOKEN not set, stdio session plays as new player %s", claims.PlayerID)
+	return claims, nil
+}
+
+// SSEMessageHandler is the SSE transport's message endpoint with resource
+// subscription support
+func (gs *MCPGameServer) SSEMessageHandler(sseServer *server.SSEServer) http.Handler {
+	return gs.subscriptions.SSEMessageHandler(sseServer.MessageHandler(), sseServer.SendEventToSession)
+}
+
+// StreamableHTTPHandler serves the streamable HTTP transport with resource
+// subscription support. Sessions are tracked by their requests, since they
+// need not open a stream, and end on DELETE or when they expire.
+func (gs *MCPGameServer) StreamableHTTPHandler() http.Handler {
+	next := gs.subscriptions.StreamableHandler(server.NewStreamableHTTPServer(gs.mcpServer))
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sessionID := r.Header.Get("Mcp-Session-Id")
+		claims := mcpClaimsFromContext(r.Context())
+		if sessionID != "" && claims != nil && r.Method == http.MethodPost {
+			gs.sessions.Touch(sessionID, claims.PlayerID)
+		}
+
+		next.ServeHTTP(w, r)
+
+		if sessionID != "" && r.Method == http.MethodDelete {
+			gs.endSession(sessionID, "closed")
+		}
+	})
+}
+
+// lockedWriter serializes whole-line writes from several goroutines
+type lockedWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (lw *lockedWriter) Write(p []byte) (int, error) {
+	lw.mu.Lock()
+	defer lw.mu.Unlock()
+	return lw.w.Write(p)
+}
+
+// ServeStdio serves MCP over newline-delimited JSON-RPC on in and out until
+// in is closed or ctx is done, acting as the player of claims. Subscription
+// requests are answered here; every other message goes to the mcp-go stdio
+// server.
+func (gs *MCPGameServer) ServeStdio(ctx context.Context, claims *auth.Claims, in io.Reader, out io.Writer) error {
+	if err := gs.joinPlayer(claims.PlayerID); err != nil {
+		return err
+	}
+	ctx = withMCPClaims(ctx, claims)
+
+	stdout := &lockedWriter{w: out}
+	messages, forward := io.Pipe()
+
+	go func() {
+		scanner := bufio.NewScanner(in)
+		scanner.Buffer(make([]byte, 0, 64*1024), 1<<20)
+		for scanner.Scan() {
+			line := scanner.Bytes()
+			if response, ok := gs.subscriptions.handleMessage(stdioSessionID, line); ok {
+				data, err := json.Marshal(response)
+				if err != nil {
+					log.Printf("❌ Failed to marshal MCP response: %v", err)
+					continue
+				}
+				stdout.Write(append(data, '\n'))
+				continue
+			}
+			if _, err := forward.Write(line); err != nil {
+				return
+			}
+			forward.Write([]byte{'\n'})
+		}
+		forward.CloseWithError(scanner.Err())
+	}()
+
+	log.Printf("🎮 MCP server listening on stdio")
+	return server.NewStdioServer(gs.mcpServer).Listen(ctx, messages, stdout)
+}
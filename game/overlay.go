@@ -0,0 +1,97 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+
+	pages "server/ui/pages/game"
+
+	"github.com/go-chi/chi/v5"
+	datastar "github.com/starfederation/datastar/sdk/go"
+)
+
+// SetupOverlayRoutes mounts the /overlay browser source and its SSE stream.
+// Like spectators, overlays never create a player.
+func SetupOverlayRoutes(router chi.Router) {
+	router.With(rateLimiter.Limit).Get("/overlay", func(w http.ResponseWriter, r *http.Request) {
+		layout, ok := overlayLayout(r)
+		if !ok {
+			http.Error(w, "layout must be grid, leaderboard or ticker", http.StatusBadRequest)
+			return
+		}
+
+		gameState, err := natsGameManager.GetGameState()
+		if err != nil {
+			http.Error(w, "Failed to get game state", http.StatusInternalServerError)
+			return
+		}
+
+		pages.OverlayPage(gameState, layout, captureFeed.Recent(), requestPalette(r)).Render(r.Context(), w)
+	})
+
+	router.With(rateLimiter.Limit).Get("/api/overlay/sse", func(w http.ResponseWriter, r *http.Request) {
+		layout, ok := overlayLayout(r)
+		if !ok {
+			http.Error(w, "layout must be grid, leaderboard or ticker", http.StatusBadRequest)
+			return
+		}
+
+		sub := broadcastHub.Subscribe()
+		defer broadcastHub.Unsubscribe(sub)
+		captures := captureFeed.Subscribe()
+		defer captureFeed.Unsubscribe(captures)
+
+		sse := datastar.NewSSE(w, r)
+
+		sentVersion, _ := strconv.ParseUint(r.Header.Get("Last-Event-ID"), 10, 64)
+
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+
+			case <-serverShutdown:
+				return
+
+			case <-captures:
+				sse.MergeFragmentTempl(pages.CaptureFeedComponent(captureFeed.Recent()))
+
+			case frame := <-sub.frames:
+				switch layout {
+				case pages.OverlayLayoutGrid:
+					if gridHTML, err := broadcastHub.gridUpdate(sentVersion, frame); err == nil && gridHTML != "" {
+						sse.MergeFragments(gridHTML)
+					}
+				case pages.OverlayLayoutLeaderboard:
+					if fragments, err := frame.fragments(r.Context()); err == nil {
+						sse.MergeFragments(fragments.leaderboardHTML)
+					}
+				case pages.OverlayLayoutTicker:
+					sse.MergeFragmentTempl(pages.OverlayTickerComponent(frame.state))
+				}
+				sse.MergeFragmentTempl(pages.OverlayTimerComponent(frame.state))
+
+				gameState := frame.state
+				eventID := strconv.FormatUint(frame.version, 10)
+				sse.MarshalAndMergeSignals(map[string]interface{}{
+					"roundState": gameState.RoundState,
+					"roundTime":  int(gameState.RoundTimeRemaining.Seconds()),
+					"countdown":  int(gameState.Countdown.Seconds()),
+				}, datastar.WithMergeSignalsEventID(eventID))
+				sentVersion = frame.version
+			}
+		}
+	})
+}
+
+// overlayLayout reads ?layout=, defaulting to the grid
+func overlayLayout(r *http.Request) (string, bool) {
+	switch layout := r.URL.Query().Get("layout"); layout {
+	case "":
+		return pages.OverlayLayoutGrid, true
+	case pages.OverlayLayoutGrid, pages.OverlayLayoutLeaderboard, pages.OverlayLayoutTicker:
+		return layout, true
+	default:
+		return "", false
+	}
+}
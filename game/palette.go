@@ -0,0 +1,116 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"server/types"
+	pages "server/ui/pages/game"
+
+	"github.com/go-chi/chi/v5"
+	datastar "github.com/starfederation/datastar/sdk/go"
+)
+
+// teamPatterns are the textures the pattern palette lays over team colors,
+// in types.TeamIDs order
+var teamPatterns = []string{
+	"background-image: repeating-linear-gradient(45deg, rgba(0, 0, 0, 0.35) 0 2px, transparent 2px 6px);",
+	"background-image: radial-gradient(circle, rgba(0, 0, 0, 0.45) 1.5px, transparent 2px); background-size: 6px 6px;",
+	"background-image: repeating-linear-gradient(0deg, rgba(0, 0, 0, 0.35) 0 2px, transparent 2px 6px);",
+	"background-image: repeating-linear-gradient(45deg, rgba(0, 0, 0, 0.3) 0 1px, transparent 1px 5px), repeating-linear-gradient(-45deg, rgba(0, 0, 0, 0.3) 0 1px, transparent 1px 5px);",
+}
+
+// paletteStylesheets holds the generated CSS of every palette by ID
+var paletteStylesheets = func() map[string]string {
+	sheets := make(map[string]string, len(types.Palettes))
+	for _, p := range types.Palettes {
+		sheets[p.ID] = paletteCSS(p)
+	}
+	return sheets
+}()
+
+// paletteCSS generates the team-bg-* and team-text-* rules for a palette,
+// picking black or white badge text for contrast
+func paletteCSS(p *types.Palette) string {
+	var css strings.Builder
+	fmt.Fprintf(&css, "/* Team palette: %s */\n", p.ID)
+	for i, teamID := range types.TeamIDs {
+		hex := p.Colors[teamID]
+		fmt.Fprintf(&css, ".team-bg-%s { background-color: %s; }\n", teamID, hex)
+		fmt.Fprintf(&css, ".team-text-%s { color: %s; }\n", teamID, hex)
+		fmt.Fprintf(&css, ".team-badge.team-bg-%s { color: %s; }\n", teamID, contrastText(hex))
+		if p.Patterns {
+			fmt.Fprintf(&css, ".team-bg-%s { %s }\n", teamID, teamPatterns[i%len(teamPatterns)])
+		}
+	}
+	return css.String()
+}
+
+// contrastText returns black for light colors and white for dark ones
+func contrastText(hex string) string {
+	c := parseHexColor(hex)
+	luminance := 0.299*float64(c.R) + 0.587*float64(c.G) + 0.114*float64(c.B)
+	if luminance > 160 {
+		return "#0f172a"
+	}
+	return "#ffffff"
+}
+
+// requestPalette reads ?palette=, falling back to the default palette
+func requestPalette(r *http.Request) string {
+	if _, ok := types.GetPalette(r.URL.Query().Get("palette")); ok {
+		return r.URL.Query().Get("palette")
+	}
+	return types.PaletteDefault
+}
+
+// playerPalette returns the palette a player chose, or the default
+func playerPalette(playerID string) string {
+	prefs, err := natsGameManager.GetPlayerPrefs(playerID)
+	if err != nil {
+		log.Printf("⚠️ Failed to load preferences for %s: %v", playerID, err)
+	}
+	return prefs.Palette
+}
+
+// SetupPaletteRoutes mounts the generated palette stylesheets and the
+// endpoint players choose their palette with
+func SetupPaletteRoutes(router chi.Router) {
+	router.Get("/palettes/{file}", func(w http.ResponseWriter, r *http.Request) {
+		css, ok := paletteStylesheets[strings.TrimSuffix(chi.URLParam(r, "file"), ".css")]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/css; charset=utf-8")
+		w.Header().Set("Cache-Control", "public, max-age=3600")
+		w.Write([]byte(css))
+	})
+
+	router.With(rateLimiter.Limit).Post("/api/palette", func(w http.ResponseWriter, r *http.Request) {
+		claims, err := authenticatePlayer(r)
+		if err != nil {
+			writePlayerIDError(w, err)
+			return
+		}
+
+		paletteID := r.URL.Query().Get("palette")
+		if err := natsGameManager.SetPlayerPalette(claims.PlayerID, paletteID); err != nil {
+			if errors.Is(err, types.ErrUnknownPalette) {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			log.Printf("❌ Failed to save palette for %s: %v", claims.PlayerID, err)
+			http.Error(w, "Failed to save palette", http.StatusInternalServerError)
+			return
+		}
+		log.Printf("🎨 Player %s switched to the %s palette", claims.PlayerID, paletteID)
+
+		sse := datastar.NewSSE(w, r)
+		sse.MergeFragmentTempl(pages.PaletteStylesheetComponent(paletteID))
+		sse.MergeFragmentTempl(pages.PaletteSelectComponent(paletteID))
+	})
+}
@@ -0,0 +1,268 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// RateLimitConfig controls the HTTP-layer token buckets
+type RateLimitConfig struct {
+	// Requests per second and burst allowed for a single player
+	PlayerRate  rate.Limit
+	PlayerBurst int
+
+	// Requests per second and burst allowed for a single client IP
+	IPRate  rate.Limit
+	IPBurst int
+
+	// New cookie identities a single client IP may mint
+	IdentityRate  rate.Limit
+	IdentityBurst int
+
+	// Buckets unused for this long are evicted
+	IdleTTL time.Duration
+
+	// Header carrying the client IP set by a trusted proxy (e.g. Fly-Client-IP).
+	// When empty the connection's remote address is used.
+	IPHeader string
+}
+
+// DefaultRateLimitConfig returns limits sized around the game rules: a player
+// can act once per ActionCooldown and hold MaxBits actions in reserve.
+func DefaultRateLimitConfig() *RateLimitConfig {
+	return &RateLimitConfig{
+		PlayerRate:    5,
+		PlayerBurst:   10,
+		IPRate:        20,
+		IPBurst:       40,
+		IdentityRate:  rate.Every(6 * time.Second), // 10 per minute
+		IdentityBurst: 5,
+		IdleTTL:       10 * time.Minute,
+	}
+}
+
+// RateLimitConfigFromEnv applies RATE_LIMIT_* overrides to the defaults
+func RateLimitConfigFromEnv() *RateLimitConfig {
+	config := DefaultRateLimitConfig()
+	config.PlayerRate = rate.Limit(envFloat("RATE_LIMIT_PLAYER_RPS", float64(config.PlayerRate)))
+	config.PlayerBurst = envInt("RATE_LIMIT_PLAYER_BURST", config.PlayerBurst)
+	config.IPRate = rate.Limit(envFloat("RATE_LIMIT_IP_RPS", float64(config.IPRate)))
+	config.IPBurst = envInt("RATE_LIMIT_IP_BURST", config.IPBurst)
+	if perMinute := envFloat("RATE_LIMIT_IDENTITIES_PER_MINUTE", 0); perMinute > 0 {
+		config.IdentityRate = rate.Limit(perMinute / 60)
+	}
+	config.IdentityBurst = envInt("RATE_LIMIT_IDENTITY_BURST", config.IdentityBurst)
+	config.IPHeader = os.Getenv("RATE_LIMIT_IP_HEADER")
+	return config
+}
+
+// RateLimiter holds per-player, per-IP and identity-creation buckets
+type RateLimiter struct {
+	players    *keyedLimiter
+	ips        *keyedLimiter
+	identities *keyedLimiter
+	ipHeader   string
+}
+
+// NewRateLimiter creates the limiter and evicts idle buckets until ctx is done
+func NewRateLimiter(ctx context.Context, config *RateLimitConfig) *RateLimiter {
+	if config == nil {
+		config = DefaultRateLimitConfig()
+	}
+
+	rl := &RateLimiter{
+		players:    newKeyedLimiter(config.PlayerRate, config.PlayerBurst),
+		ips:        newKeyedLimiter(config.IPRate, config.IPBurst),
+		identities: newKeyedLimiter(config.IdentityRate, config.IdentityBurst),
+		ipHeader:   config.IPHeader,
+	}
+
+	go func() {
+		ticker := time.NewTicker(config.IdleTTL)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				rl.players.evict(config.IdleTTL)
+				rl.ips.evict(config.IdleTTL)
+				rl.identities.evict(config.IdleTTL)
+			}
+		}
+	}()
+
+	log.Printf("🚦 Rate limiting enabled: player %.1f/s (burst %d), IP %.1f/s (burst %d)",
+		float64(config.PlayerRate), config.PlayerBurst, float64(config.IPRate), config.IPBurst)
+	return rl
+}
+
+// Limit enforces the IP and player buckets before the request reaches a
+// handler.
+func (rl *RateLimiter) Limit(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ip := rl.clientIP(r)
+		if ok, retryAfter := rl.ips.allow(ip); !ok {
+			log.Printf("🚦 Rate limited IP %s on %s", ip, r.URL.Path)
+			writeRateLimited(w, retryAfter)
+			return
+		}
+
+		if playerID := requestPlayerKey(r); playerID != "" {
+			if ok, retryAfter := rl.players.allow(playerID); !ok {
+				log.Printf("🚦 Rate limited player %s on %s", playerID, r.URL.Path)
+				writeRateLimited(w, retryAfter)
+				return
+			}
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// AllowNewIdentity reports whether the client may mint another player ID
+func (rl *RateLimiter) AllowNewIdentity(r *http.Request) (bool, time.Duration) {
+	return rl.identities.allow(rl.clientIP(r))
+}
+
+// RateLimitError is returned when a request is refused by a bucket
+type RateLimitError struct {
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("rate limited, retry after %v", e.RetryAfter)
+}
+
+// writeRateLimited sends a 429 with a Retry-After header in whole seconds
+func writeRateLimited(w http.ResponseWriter, retryAfter time.Duration) {
+	seconds := int(math.Ceil(retryAfter.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+	w.Header().Set("Retry-After", strconv.Itoa(seconds))
+	http.Error(w, "Too many requests", http.StatusTooManyRequests)
+}
+
+// requestPlayerKey identifies the acting player from a verified session
+// without minting a new ID; unauthenticated requests are limited by IP only
+func requestPlayerKey(r *http.Request) string {
+	if claims, err := authenticatePlayer(r); err == nil {
+		return claims.PlayerID
+	}
+	return ""
+}
+
+// clientIP returns the trusted proxy header if configured, otherwise the host
+// part of RemoteAddr
+func (rl *RateLimiter) clientIP(r *http.Request) string {
+	if rl.ipHeader != "" {
+		if ip := r.Header.Get(rl.ipHeader); ip != "" {
+			return ip
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// keyedLimiter is a set of token buckets indexed by an arbitrary key
+type keyedLimiter struct {
+	mu      sync.Mutex
+	limit   rate.Limit
+	burst   int
+	buckets map[string]*bucket
+}
+
+type bucket struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+func newKeyedLimiter(limit rate.Limit, burst int) *keyedLimiter {
+	return &keyedLimiter{
+		limit:   limit,
+		burst:   burst,
+		buckets: make(map[string]*bucket),
+	}
+}
+
+// allow takes a token for key, or reports how long until one is available
+func (kl *keyedLimiter) allow(key string) (bool, time.Duration) {
+	return kl.allowAt(key, time.Now())
+}
+
+func (kl *keyedLimiter) allowAt(key string, now time.Time) (bool, time.Duration) {
+	kl.mu.Lock()
+	b, ok := kl.buckets[key]
+	if !ok {
+		b = &bucket{limiter: rate.NewLimiter(kl.limit, kl.burst)}
+		kl.buckets[key] = b
+	}
+	b.lastSeen = now
+	kl.mu.Unlock()
+
+	reservation := b.limiter.ReserveN(now, 1)
+	if !reservation.OK() {
+		return false, time.Minute
+	}
+	if delay := reservation.DelayFrom(now); delay > 0 {
+		reservation.CancelAt(now)
+		return false, delay
+	}
+	return true, 0
+}
+
+func (kl *keyedLimiter) evict(idle time.Duration) {
+	cutoff := time.Now().Add(-idle)
+
+	kl.mu.Lock()
+	defer kl.mu.Unlock()
+	for key, b := range kl.buckets {
+		if b.lastSeen.Before(cutoff) {
+			delete(kl.buckets, key)
+		}
+	}
+}
+
+func envFloat(name string, fallback float64) float64 {
+	if v := os.Getenv(name); v != "" {
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			return f
+		}
+		log.Printf("⚠️ Ignoring invalid %s=%q", name, v)
+	}
+	return fallback
+}
+
+func envInt(name string, fallback int) int {
+	if v := os.Getenv(name); v != "" {
+		if i, err := strconv.Atoi(v); err == nil {
+			return i
+		}
+		log.Printf("⚠️ Ignoring invalid %s=%q", name, v)
+	}
+	return fallback
+}
+
+func envDuration(name string, fallback time.Duration) time.Duration {
+	if v := os.Getenv(name); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d > 0 {
+			return d
+		}
+		log.Printf("⚠️ Ignoring invalid %s=%q", name, v)
+	}
+	return fallback
+}
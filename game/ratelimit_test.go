@@ -0,0 +1,130 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+func TestKeyedLimiterRefill(t *testing.T) {
+	start := time.Now()
+
+	tests := []struct {
+		name      string
+		limit     rate.Limit
+		burst     int
+		at        []time.Duration // offsets from start of each request
+		want      []bool
+		lastRetry time.Duration // retry-after of the last request
+	}{
+		{
+			name:  "burst then refused",
+			limit: 2, burst: 3,
+			at:        []time.Duration{0, 0, 0, 0},
+			want:      []bool{true, true, true, false},
+			lastRetry: 500 * time.Millisecond,
+		},
+		{
+			name:  "refills one token per interval",
+			limit: 2, burst: 1,
+			at:   []time.Duration{0, 0, 500 * time.Millisecond, 600 * time.Millisecond},
+			want: []bool{true, false, true, false},
+			// 100ms of the 500ms interval have passed since the last token
+			lastRetry: 400 * time.Millisecond,
+		},
+		{
+			name:  "refill is capped at burst",
+			limit: 10, burst: 2,
+			at:        []time.Duration{0, 0, time.Hour, time.Hour, time.Hour},
+			want:      []bool{true, true, true, true, false},
+			lastRetry: 100 * time.Millisecond,
+		},
+		{
+			name:  "refused requests take no token",
+			limit: 1, burst: 1,
+			at:        []time.Duration{0, 0, 0, time.Second},
+			want:      []bool{true, false, false, true},
+			lastRetry: 0,
+		},
+		{
+			name:  "zero burst never allows",
+			limit: 1, burst: 0,
+			at:        []time.Duration{0},
+			want:      []bool{false},
+			lastRetry: time.Minute,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kl := newKeyedLimiter(tt.limit, tt.burst)
+			var retry time.Duration
+			for i, offset := range tt.at {
+				var ok bool
+				ok, retry = kl.allowAt("player", start.Add(offset))
+				if ok != tt.want[i] {
+					t.Fatalf("request %d at +%v: allowed = %v, want %v", i, offset, ok, tt.want[i])
+				}
+			}
+			if retry != tt.lastRetry {
+				t.Errorf("retry after = %v, want %v", retry, tt.lastRetry)
+			}
+		})
+	}
+}
+
+func TestKeyedLimiterKeysAreIndependent(t *testing.T) {
+	kl := newKeyedLimiter(1, 1)
+	now := time.Now()
+	if ok, _ := kl.allowAt("a", now); !ok {
+		t.Fatal("first request of a refused")
+	}
+	if ok, _ := kl.allowAt("a", now); ok {
+		t.Fatal("second request of a allowed")
+	}
+	if ok, _ := kl.allowAt("b", now); !ok {
+		t.Fatal("first request of b refused after a was limited")
+	}
+}
+
+func TestKeyedLimiterEvict(t *testing.T) {
+	kl := newKeyedLimiter(1, 1)
+	kl.allowAt("stale", time.Now().Add(-time.Hour))
+	kl.allowAt("fresh", time.Now())
+
+	kl.evict(time.Minute)
+
+	if _, ok := kl.buckets["stale"]; ok {
+		t.Error("idle bucket was not evicted")
+	}
+	if _, ok := kl.buckets["fresh"]; !ok {
+		t.Error("recent bucket was evicted")
+	}
+}
+
+func TestWriteRateLimitedRetryAfter(t *testing.T) {
+	tests := []struct {
+		retryAfter time.Duration
+		want       string
+	}{
+		{0, "1"},
+		{100 * time.Millisecond, "1"},
+		{time.Second, "1"},
+		{1500 * time.Millisecond, "2"},
+		{time.Minute, "60"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.retryAfter.String(), func(t *testing.T) {
+			w := httptest.NewRecorder()
+			writeRateLimited(w, tt.retryAfter)
+			if w.Code != http.StatusTooManyRequests {
+				t.Errorf("status = %d, want %d", w.Code, http.StatusTooManyRequests)
+			}
+			if got := w.Header().Get("Retry-After"); got != tt.want {
+				t.Errorf("Retry-After = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
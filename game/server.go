@@ -4,12 +4,14 @@ import (
 	"context"
 	"embed"
 	"encoding/json"
-	"fmt"
+	"errors"
+	"flag"
 	"io/fs"
 	"log"
 	"net/http"
 	"os"
 	"path/filepath"
+	"server/i18n"
 	"server/types"
 	pages "server/ui/pages/game"
 	"strconv"
@@ -18,7 +20,6 @@ import (
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/cors"
-	"github.com/google/uuid"
 	"github.com/mark3labs/mcp-go/server"
 	datastar "github.com/starfederation/datastar/sdk/go"
 )
@@ -35,11 +36,22 @@ var mcpGameServer *MCPGameServer
 // Global MCP SSE server
 var mcpSSEServer *server.SSEServer
 
+// Global HTTP rate limiter
+var rateLimiter *RateLimiter
+
 func main() {
+	stdio := flag.Bool("stdio", false, "serve MCP over stdin/stdout, for agents that launch the server as a subprocess")
+	withHTTP := flag.Bool("http", false, "with -stdio, also serve the game and web UI on PORT")
+	flag.Parse()
+
+	// A stdio server listens on no ports unless asked to
+	listen := !*stdio || *withHTTP
+
 	ctx := context.Background()
 
 	// Initialize NATS-enhanced game manager
 	config := types.DefaultNATSConfig()
+	config.InProcess = *stdio
 	var err error
 	natsGameManager, err = types.NewNATSGameManager(ctx, config)
 	if err != nil {
@@ -50,10 +62,12 @@ func main() {
 	if err := natsGameManager.Start(); err != nil {
 		log.Fatalf("❌ Failed to start NATS game manager: %v", err)
 	}
-	defer natsGameManager.Stop()
+
+	// Initialize session signing for player identities
+	sessionSigner = newSessionSignerFromEnv()
 
 	// Initialize MCP server
-	mcpGameServer = NewMCPGameServer(natsGameManager)
+	mcpGameServer = NewMCPGameServer(natsGameManager, sessionSigner, NewMCPQuotas(ctx, natsGameManager, MCPQuotaConfigFromEnv()))
 	log.Printf("🎮 MCP server initialized with game tools")
 
 	// Get port for MCP SSE server configuration
@@ -62,33 +76,91 @@ func main() {
 		port = "3000"
 	}
 
-	// Initialize MCP SSE server
+	publicURL := publicURLFromEnv()
+	adminToken := os.Getenv("ADMIN_TOKEN")
+	mcpGameServer.EnableAdminTools(adminToken)
+	hostAPIKey := os.Getenv("HOST_API_KEY")
+
+	rateLimiter = NewRateLimiter(ctx, RateLimitConfigFromEnv())
+
+	broadcastHub, err = NewBroadcastHub(ctx, natsGameManager)
+	if err != nil {
+		log.Fatalf("❌ Failed to start broadcast hub: %v", err)
+	}
+
+	natsGameManager.SetChatFilter(chatFilterFromEnv())
+
+	captureFeed, err = NewCaptureFeed(natsGameManager)
+	if err != nil {
+		log.Fatalf("❌ Failed to start capture feed: %v", err)
+	}
+
+	contestHeatmap, err = NewHeatmap(ctx, natsGameManager)
+	if err != nil {
+		log.Fatalf("❌ Failed to start heatmap: %v", err)
+	}
+
+	botManager, err = NewBotManager(ctx, natsGameManager, broadcastHub, BotConfigFromEnv())
+	if err != nil {
+		log.Fatalf("❌ Failed to start bot runtime: %v", err)
+	}
+
+	if err := mcpGameServer.WatchResources(ctx, mcpNotifyIntervalFromEnv()); err != nil {
+		log.Fatalf("❌ Failed to start MCP resource notifications: %v", err)
+	}
+	mcpGameServer.ExpireSessions(ctx, envDuration("MCP_SESSION_IDLE_TIMEOUT", defaultMCPSessionIdleTimeout))
+
+	router := chi.NewRouter()
+	httpServer := &http.Server{
+		Addr:    ":" + port,
+		Handler: router,
+	}
+
+	// Initialize MCP SSE server. Without PUBLIC_URL the message endpoint is
+	// sent as a path, which clients resolve against the host they connected to.
 	mcpSSEServer = server.NewSSEServer(
 		mcpGameServer.GetMCPServer(),
-		server.WithBaseURL(fmt.Sprintf("http://localhost:%s", port)),
+		server.WithHTTPServer(httpServer),
+		server.WithBaseURL(publicURL),
 		server.WithStaticBasePath("/mcp"),
 		server.WithSSEEndpoint("/sse"),
 		server.WithMessageEndpoint("/message"),
 		server.WithKeepAlive(true),
 	)
 
-	router := chi.NewRouter()
-
-	router.Use(middleware.Logger)
+	router.Use(redactTokenQuery)
+	if *stdio {
+		// stdout carries the MCP protocol, so request logs go to stderr
+		router.Use(middleware.RequestLogger(&middleware.DefaultLogFormatter{Logger: log.Default(), NoColor: true}))
+	} else {
+		router.Use(middleware.Logger)
+	}
 	router.Use(middleware.Recoverer)
 	router.Use(cors.Handler(cors.Options{
 		AllowedOrigins:   []string{"*"},
-		AllowedMethods:   []string{"GET", "POST", "OPTIONS"},
+		AllowedMethods:   []string{"GET", "POST", "DELETE", "OPTIONS"},
 		AllowedHeaders:   []string{"*"},
 		AllowCredentials: false,
 	}))
+	router.Use(i18n.Middleware)
 
 	SetupAssetsRoutes(router)
-
-	router.Get("/", func(w http.ResponseWriter, r *http.Request) {
+	SetupHealthRoutes(router)
+	SetupAdminRoutes(router, adminToken)
+	SetupSessionRoutes(router, hostAPIKey)
+	SetupSpectatorRoutes(router)
+	SetupImageRoutes(router)
+	SetupOverlayRoutes(router)
+	SetupChatRoutes(router)
+	SetupHeatmapRoutes(router)
+	SetupPaletteRoutes(router)
+	SetupAPIRoutes(router)
+	SetupBotRoutes(router)
+
+	router.With(rateLimiter.Limit).Get("/", func(w http.ResponseWriter, r *http.Request) {
 		playerID, err := getPlayerID(w, r)
 		if err != nil {
-			http.Error(w, "Failed to get player ID", http.StatusInternalServerError)
+			writePlayerIDError(w, err)
 			return
 		}
 
@@ -96,6 +168,19 @@ func main() {
 		player, _ := natsGameManager.GetPlayer(playerID)
 		if player == nil {
 			player, err = natsGameManager.AddPlayer(playerID)
+			if errors.Is(err, types.ErrPlayerBanned) {
+				http.Error(w, "You have been banned from this game", http.StatusForbidden)
+				return
+			}
+			if errors.Is(err, types.ErrPlayerKicked) {
+				http.Error(w, "You were kicked from this game; try again in a few minutes", http.StatusForbidden)
+				return
+			}
+			if errors.Is(err, types.ErrTournamentInProgress) {
+				// Humans watch tournament rounds
+				http.Redirect(w, r, "/spectate", http.StatusSeeOther)
+				return
+			}
 			if err != nil {
 				log.Printf("❌ Failed to add player: %v", err)
 				http.Error(w, "Failed to add player", http.StatusInternalServerError)
@@ -111,20 +196,27 @@ func main() {
 			return
 		}
 
-		pages.GamePage(player, gameState).Render(r.Context(), w)
+		chatHistory := teamChatHistory(r.Context(), player.TeamID)
+		pages.GamePage(player, gameState, sessionTokenForPage(playerID), chatHistory, playerPalette(playerID)).Render(r.Context(), w)
 	})
 
-	router.Get("/api/sse", func(w http.ResponseWriter, r *http.Request) {
-		playerID := r.URL.Query().Get("playerId")
-		if playerID == "" {
-			http.Error(w, "playerId is required", http.StatusBadRequest)
+	router.With(rateLimiter.Limit).Get("/api/sse", func(w http.ResponseWriter, r *http.Request) {
+		claims, err := authenticatePlayer(r)
+		if err != nil {
+			writePlayerIDError(w, err)
 			return
 		}
-
-		sse := datastar.NewSSE(w, r)
+		playerID := claims.PlayerID
 
 		// Add or reconnect player. This will set IsConnected = true.
-		if _, err := natsGameManager.AddPlayer(playerID); err != nil {
+		player, err := natsGameManager.AddPlayer(playerID)
+		if errors.Is(err, types.ErrPlayerBanned) {
+			http.Error(w, "You have been banned from this game", http.StatusForbidden)
+			return
+		} else if errors.Is(err, types.ErrPlayerKicked) || errors.Is(err, types.ErrTournamentInProgress) {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		} else if err != nil {
 			log.Printf("❌ Failed to add or reconnect player: %v", err)
 			http.Error(w, "Failed to add player", http.StatusInternalServerError)
 			return
@@ -132,19 +224,35 @@ func main() {
 
 		log.Printf("🔗 Player %s connected via SSE", playerID)
 
-		// Watch game state changes from NATS KV store
-		watcher, err := natsGameManager.WatchGameState()
+		// Rendered state versions come from the shared broadcast hub
+		sub := broadcastHub.Subscribe()
+		defer broadcastHub.Unsubscribe(sub)
+
+		sse := datastar.NewSSE(w, r)
+
+		sseSubscribers.Add(1)
+		defer sseSubscribers.Add(-1)
+
+		// Team chat arrives on the team's subject; resync history on (re)connect
+		chat := make(chan *types.ChatMessage, 16)
+		unsubscribeChat, err := natsGameManager.SubscribeTeamChat(player.TeamID, func(m *types.ChatMessage) {
+			select {
+			case chat <- m:
+			default:
+			}
+		})
 		if err != nil {
-			log.Printf("❌ Failed to create game state watcher: %v", err)
-			http.Error(w, "Failed to watch game state", http.StatusInternalServerError)
-			return
+			log.Printf("⚠️ Team chat unavailable for player %s: %v", playerID, err)
+		} else {
+			defer unsubscribeChat()
 		}
-		defer watcher.Stop()
+		sse.MergeFragmentTempl(pages.ChatMessagesComponent(teamChatHistory(r.Context(), player.TeamID), playerID))
 
-		// Send initial game state
-		gameState, err := natsGameManager.GetGameState()
-		if err == nil {
-			sendGameStateUpdate(sse, gameState, playerID)
+		// Version of the last frame the client applied, so the grid can be
+		// patched cell by cell. A reconnecting client reports it as Last-Event-ID.
+		sentVersion, _ := strconv.ParseUint(r.Header.Get("Last-Event-ID"), 10, 64)
+		if sentVersion != 0 {
+			log.Printf("🔁 Player %s resuming from version %d", playerID, sentVersion)
 		}
 
 		// Listen for context cancellation and game state updates
@@ -155,32 +263,37 @@ func main() {
 				log.Printf("🔌 Player %s disconnected, marked as idle", playerID)
 				return
 
-			case entry := <-watcher.Updates():
-				if entry == nil {
-					continue
-				}
+			case <-serverShutdown:
+				sse.MergeFragmentTempl(pages.ServerRestartingComponent())
+				log.Printf("🔌 Closing stream for player %s, server restarting", playerID)
+				return
 
-				// Parse game state from KV entry
-				var snapshot types.GameStateSnapshot
-				if err := json.Unmarshal(entry.Value(), &snapshot); err != nil {
-					log.Printf("❌ Failed to unmarshal game state: %v", err)
-					continue
+			case m := <-chat:
+				if natsGameManager.Removed(playerID) {
+					log.Printf("👢 Closing stream for removed player %s", playerID)
+					return
 				}
+				sse.MergeFragmentTempl(pages.ChatMessageComponent(m, playerID), datastar.WithSelectorID("team-chat-messages"), datastar.WithMergeAppend())
 
-				// Convert snapshot back to GameState for rendering
-				gameState := convertSnapshotToGameState(&snapshot)
-				sendGameStateUpdate(sse, gameState, playerID)
+			case frame := <-sub.frames:
+				if natsGameManager.Removed(playerID) {
+					log.Printf("👢 Closing stream for removed player %s", playerID)
+					return
+				}
+				sendGameStateUpdate(sse, frame, sentVersion, playerID)
+				sentVersion = frame.version
 			}
 		}
 	})
 
-	router.Post("/action", func(w http.ResponseWriter, r *http.Request) {
-		playerID, err := getPlayerID(w, r)
+	router.With(rateLimiter.Limit).Post("/action", func(w http.ResponseWriter, r *http.Request) {
+		claims, err := authenticatePlayer(r)
 		if err != nil {
-			log.Printf("❌ Failed to get player ID: %v", err)
-			http.Error(w, "Failed to get player ID", http.StatusInternalServerError)
+			log.Printf("❌ Failed to authenticate action: %v", err)
+			writePlayerIDError(w, err)
 			return
 		}
+		playerID := claims.PlayerID
 
 		x, _ := strconv.Atoi(r.URL.Query().Get("x"))
 		y, _ := strconv.Atoi(r.URL.Query().Get("y"))
@@ -189,6 +302,11 @@ func main() {
 
 		// Use NATS manager to place bit
 		success, err := natsGameManager.PlaceBit(playerID, x, y)
+		if errors.Is(err, types.ErrShuttingDown) {
+			w.Header().Set("Retry-After", "5")
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
 		if err != nil {
 			log.Printf("❌ Action failed for player %s: %v", playerID, err)
 			http.Error(w, err.Error(), http.StatusBadRequest)
@@ -203,7 +321,7 @@ func main() {
 	})
 
 	// Player status endpoints
-	router.Post("/api/player/{playerID}/active", func(w http.ResponseWriter, r *http.Request) {
+	router.With(rateLimiter.Limit, requireSelf).Post("/api/player/{playerID}/active", func(w http.ResponseWriter, r *http.Request) {
 		log.Printf("🔄 Setting player %s active", chi.URLParam(r, "playerID"))
 		playerID := chi.URLParam(r, "playerID")
 		if err := natsGameManager.SetPlayerActive(playerID); err != nil {
@@ -213,7 +331,7 @@ func main() {
 		w.WriteHeader(http.StatusOK)
 	})
 
-	router.Post("/api/player/{playerID}/idle", func(w http.ResponseWriter, r *http.Request) {
+	router.With(rateLimiter.Limit, requireSelf).Post("/api/player/{playerID}/idle", func(w http.ResponseWriter, r *http.Request) {
 		log.Printf("🔄 Setting player %s idle", chi.URLParam(r, "playerID"))
 		playerID := chi.URLParam(r, "playerID")
 		if err := natsGameManager.SetPlayerIdle(playerID); err != nil {
@@ -223,21 +341,28 @@ func main() {
 		w.WriteHeader(http.StatusOK)
 	})
 
-	// Mount MCP SSE endpoints
-	router.Handle("/mcp/sse", mcpSSEServer.SSEHandler())
-	router.Handle("/mcp/message", mcpSSEServer.MessageHandler())
+	// Mount MCP SSE and streamable HTTP endpoints
+	router.Handle("/mcp/sse", mcpGameServer.RequireSession(mcpSSEServer.SSEHandler()))
+	router.Handle("/mcp/message", mcpGameServer.SSEMessageHandler(mcpSSEServer))
+	router.Handle("/mcp/stream", mcpGameServer.RequireSession(mcpGameServer.StreamableHTTPHandler()))
 
 	// MCP info endpoint
 	router.Get("/mcp", func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
+		baseURL := requestBaseURL(r, publicURL)
+		adminTools := []string{}
+		if adminToken != "" {
+			adminTools = []string{"tournament_register_agent", "tournament_remove_agent", "tournament_start", "tournament_stop"}
+		}
 		response := map[string]interface{}{
 			"name":        "BitSplat Game Server",
 			"version":     "1.0.0",
 			"description": "MCP server for BitSplat game with NATS backend",
-			"transport":   "sse",
+			"transports":  []string{"sse", "streamable-http", "stdio"},
 			"endpoints": map[string]string{
-				"sse":     fmt.Sprintf("http://localhost:%s/mcp/sse", port),
-				"message": fmt.Sprintf("http://localhost:%s/mcp/message", port),
+				"sse":            baseURL + "/mcp/sse",
+				"message":        baseURL + "/mcp/message",
+				"streamableHttp": baseURL + "/mcp/stream",
 			},
 			"tools": []string{
 				"place_bit",
@@ -245,25 +370,74 @@ func main() {
 				"get_player_state",
 				"add_player",
 				"get_team_info",
+				"get_heatmap",
+				"get_grid_region",
+				"find_cells",
+				"get_recent_events",
+				"place_bits",
+				"get_cooldown_status",
+				"get_tournament",
+			},
+			"adminTools": adminTools,
+			"prompts": []string{
+				"explain_rules",
+				"analyze_board",
+				"plan_next_moves",
+				"post_round_review",
 			},
 			"resources": []string{
 				"game://state",
 			},
+			"resourceSubscriptions": true,
+			"resourceTemplates": []string{
+				"game://player/{id}",
+				"game://team/{id}",
+				"game://grid/{x0},{y0}-{x1},{y1}",
+				"game://rounds/{id}",
+			},
 		}
 		json.NewEncoder(w).Encode(response)
 	})
 
-	log.Printf("🚀 Starting BitSplat: The Game server with NATS + MCP on http://localhost:%s", port)
-	log.Printf("🎮 MCP SSE endpoint: http://localhost:%s/mcp/sse", port)
-	log.Printf("🎮 MCP message endpoint: http://localhost:%s/mcp/message", port)
-	log.Printf("🎮 MCP tools available: place_bit, get_game_state, get_player_state, add_player, get_team_info")
-	log.Fatal(http.ListenAndServe(":"+port, router))
-}
+	baseURL := publicURL
+	if baseURL == "" {
+		baseURL = "http://localhost:" + port
+	}
+	if listen {
+		log.Printf("🚀 Starting BitSplat: The Game server with NATS + MCP on %s", baseURL)
+		log.Printf("🎮 MCP SSE endpoint: %s/mcp/sse", baseURL)
+		log.Printf("🎮 MCP message endpoint: %s/mcp/message", baseURL)
+		log.Printf("🎮 MCP streamable HTTP endpoint: %s/mcp/stream", baseURL)
+		log.Printf("🩺 Health probes: %s/healthz, %s/readyz", baseURL, baseURL)
+	} else {
+		log.Printf("🚀 Starting BitSplat: The Game server with NATS + MCP on stdio only; pass -http to also serve %s", baseURL)
+	}
+	log.Printf("🎮 MCP tools available: place_bit, get_game_state, get_player_state, add_player, get_team_info, get_heatmap, get_grid_region, find_cells, get_recent_events, place_bits, get_cooldown_status, get_tournament")
 
-// sendGameStateUpdate sends game state updates to a specific player via SSE
-func sendGameStateUpdate(sse *datastar.ServerSentEventGenerator, gameState *types.GameState, playerID string) {
-	log.Printf("🔄 Sending game state update to player %s", playerID)
+	var stdioDone chan error
+	if *stdio {
+		claims, err := stdioClaims(sessionSigner)
+		if err != nil {
+			log.Fatalf("❌ %v", err)
+		}
+		stdioDone = make(chan error, 1)
+		go func() {
+			stdioDone <- mcpGameServer.ServeStdio(ctx, claims, os.Stdin, os.Stdout)
+		}()
+	}
+	if !listen {
+		httpServer = nil
+	}
+	if err := serveUntilSignal(httpServer, shutdownTimeoutFromEnv(), stdioDone); err != nil {
+		log.Fatal(err)
+	}
+}
 
+// sendGameStateUpdate sends a rendered state version to a specific player via
+// SSE. The grid is patched from the client's last version when the hub still
+// has the deltas, otherwise sent in full. The final event carries the version
+// as its SSE id so a reconnecting client can resume from it.
+func sendGameStateUpdate(sse *datastar.ServerSentEventGenerator, frame *hubFrame, sentVersion uint64, playerID string) {
 	// Get the specific player and team from NATS manager (source of truth)
 	player, team := natsGameManager.GetPlayer(playerID)
 	if player == nil {
@@ -271,52 +445,13 @@ func sendGameStateUpdate(sse *datastar.ServerSentEventGenerator, gameState *type
 		return
 	}
 
-	// Send individual component updates
-	gridHTML := pages.GridComponent(gameState)
-	sse.MergeFragmentTempl(gridHTML)
-
-	playerHudHTML := pages.PlayerHUD(player, team)
-	sse.MergeFragmentTempl(playerHudHTML)
-
-	leaderboardHTML := pages.LeaderboardComponent(gameState)
-	sse.MergeFragmentTempl(leaderboardHTML)
-
-	roundStatusHTML := pages.RoundStatusComponent(gameState)
-	sse.MergeFragmentTempl(roundStatusHTML)
-
-	// Create serializable game state for client-side bot
-	serializableGrid := make([][]string, types.GridHeight)
-	for y := 0; y < types.GridHeight; y++ {
-		serializableGrid[y] = make([]string, types.GridWidth)
-		for x := 0; x < types.GridWidth; x++ {
-			key := fmt.Sprintf("%d:%d", x, y)
-			if cell, ok := gameState.Grid.Load(key); ok {
-				serializableGrid[y][x] = cell.(types.Cell).OwnerID
-			} else {
-				serializableGrid[y][x] = "neutral"
-			}
-		}
-	}
+	sendSharedFragments(sse, frame, sentVersion)
 
-	teamsInfo := make(map[string]map[string]interface{})
-	for teamID, t := range gameState.Teams {
-		teamsInfo[teamID] = map[string]interface{}{
-			"score": t.Score,
-		}
-	}
+	// The HUD is the only per-player fragment
+	sse.MergeFragmentTempl(pages.PlayerHUD(player, team))
 
-	clientGameState := map[string]interface{}{
-		"grid":               serializableGrid,
-		"teams":              teamsInfo,
-		"roundState":         gameState.RoundState,
-		"roundTimeRemaining": int(gameState.RoundTimeRemaining.Seconds()),
-		"countdown":          int(gameState.Countdown.Seconds()),
-		"player": map[string]interface{}{
-			"id":     player.ID,
-			"bits":   player.Bits,
-			"teamId": player.TeamID,
-		},
-	}
+	gameState := frame.state
+	clientGameState := botGameState(frame, player)
 
 	// Send signal updates for reactive parts
 	signals := map[string]interface{}{
@@ -329,11 +464,47 @@ func sendGameStateUpdate(sse *datastar.ServerSentEventGenerator, gameState *type
 	sse.MarshalAndMergeSignals(signals)
 
 	// Dispatch custom event for the bot
-	if err := sse.DispatchCustomEvent("game:state:updated", clientGameState); err != nil {
+	eventID := strconv.FormatUint(frame.version, 10)
+	if err := sse.DispatchCustomEvent("game:state:updated", clientGameState, datastar.WithDispatchCustomEventEventID(eventID)); err != nil {
 		log.Printf("🚨 Error dispatching custom event to player %s: %v", playerID, err)
 	}
+}
+
+// botGameState is the state a bot sees through getState(), in the browser and
+// on the server
+func botGameState(frame *hubFrame, player *types.Player) map[string]interface{} {
+	gameState := frame.state
+	return map[string]interface{}{
+		"grid":               frame.gridJSON,
+		"teams":              frame.teamsInfo,
+		"roundState":         gameState.RoundState,
+		"roundTimeRemaining": int(gameState.RoundTimeRemaining.Seconds()),
+		"countdown":          int(gameState.Countdown.Seconds()),
+		"player": map[string]interface{}{
+			"id":     player.ID,
+			"bits":   player.Bits,
+			"teamId": player.TeamID,
+		},
+	}
+}
+
+// sendSharedFragments sends the grid, leaderboard and round status of a frame,
+// patching the grid from sentVersion when possible
+func sendSharedFragments(sse *datastar.ServerSentEventGenerator, frame *hubFrame, sentVersion uint64) {
+	if gridHTML, err := broadcastHub.gridUpdate(sentVersion, frame); err != nil {
+		log.Printf("🚨 Error rendering grid update: %v", err)
+		sse.MergeFragments(frame.gridHTML)
+	} else if gridHTML != "" {
+		sse.MergeFragments(gridHTML)
+	}
 
-	log.Printf("📤 Sent component updates to player %s", playerID)
+	fragments, err := frame.fragments(sse.Context())
+	if err != nil {
+		log.Printf("🚨 Error rendering localized fragments: %v", err)
+		return
+	}
+	sse.MergeFragments(fragments.leaderboardHTML)
+	sse.MergeFragments(fragments.roundStatusHTML)
 }
 
 // convertSnapshotToGameState converts a GameStateSnapshot back to GameState
@@ -345,6 +516,8 @@ func convertSnapshotToGameState(snapshot *types.GameStateSnapshot) *types.GameSt
 		RoundTimeRemaining: snapshot.RoundTimeRemaining,
 		Countdown:          snapshot.Countdown,
 		Winner:             snapshot.Winner,
+		Paused:             snapshot.Paused,
+		RoundID:            snapshot.RoundID,
 	}
 
 	// Convert grid map back to sync.Map
@@ -365,35 +538,6 @@ func getPlayerFromState(gameState *types.GameState, playerID string) (*types.Pla
 	return nil, nil
 }
 
-func getPlayerID(w http.ResponseWriter, r *http.Request) (string, error) {
-	// First, check for userId in URL parameters (for webview and MCP integration)
-	if userID := r.URL.Query().Get("userId"); userID != "" {
-		log.Printf("🆔 Using URL parameter user ID: %s", userID)
-		return userID, nil
-	}
-
-	// Fallback to cookie-based ID for direct browser access
-	cookie, err := r.Cookie("player_id")
-	if err == http.ErrNoCookie {
-		playerID := uuid.New().String()[:8]
-		http.SetCookie(w, &http.Cookie{
-			Name:     "player_id",
-			Value:    playerID,
-			Path:     "/",
-			HttpOnly: true,
-			MaxAge:   60 * 60 * 24 * 365, // 1 year
-		})
-		log.Printf("🆕 New player created: %s", playerID)
-		return playerID, nil
-	}
-	if err != nil {
-		return "", err
-	}
-
-	log.Printf("🆔 Using cookie-based user ID: %s", cookie.Value)
-	return cookie.Value, nil
-}
-
 func SetupAssetsRoutes(router chi.Router) {
 	var isDevelopment = os.Getenv("GO_ENV") != "production"
 
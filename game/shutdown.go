@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// serverShutdown is closed when the process starts shutting down so that
+// long-lived SSE handlers can notify their clients and return
+var serverShutdown = make(chan struct{})
+
+// shutdownTimeoutFromEnv reads SHUTDOWN_TIMEOUT (default 20s)
+func shutdownTimeoutFromEnv() time.Duration {
+	if v := os.Getenv("SHUTDOWN_TIMEOUT"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d > 0 {
+			return d
+		}
+		log.Printf("⚠️ Ignoring invalid SHUTDOWN_TIMEOUT=%q", v)
+	}
+	return 20 * time.Second
+}
+
+// serveUntilSignal runs httpServer (nil when only serving stdio) until
+// SIGINT/SIGTERM, a listen error or stdioDone firing (the stdio MCP client
+// went away; nil when not serving stdio), then shuts everything down in order
+// within timeout:
+//
+//  1. stop accepting player actions
+//  2. send SSE clients a "server restarting" fragment and end their streams
+//  3. close MCP sessions and streams and stop the HTTP server, waiting for
+//     in-flight requests
+//  4. flush state to KV, stop the game loop and shut down NATS
+//
+// The returned error is the listen error, if serving failed.
+func serveUntilSignal(httpServer *http.Server, timeout time.Duration, stdioDone <-chan error) error {
+	sigCtx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var serveErr error
+	serveDone := make(chan error, 1)
+	if httpServer != nil {
+		go func() {
+			serveDone <- httpServer.ListenAndServe()
+		}()
+	}
+
+	select {
+	case err := <-serveDone:
+		if !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("❌ HTTP server failed: %v", err)
+			serveErr = err
+		}
+	case <-sigCtx.Done():
+		log.Printf("🛑 Shutdown signal received, draining (deadline %v)", timeout)
+	case err := <-stdioDone:
+		if err != nil {
+			log.Printf("❌ MCP stdio server failed: %v", err)
+		}
+		log.Printf("🛑 MCP stdio client disconnected, draining (deadline %v)", timeout)
+	}
+	stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	natsGameManager.Drain()
+	close(serverShutdown)
+
+	// Streamable HTTP streams are plain handlers nothing else would end.
+	// The SSE server owns httpServer (WithHTTPServer), so this also stops it.
+	mcpGameServer.CloseStreams()
+	if err := mcpSSEServer.Shutdown(ctx); err != nil {
+		log.Printf("⚠️ HTTP server shutdown: %v", err)
+	}
+
+	if err := natsGameManager.Shutdown(ctx); err != nil {
+		log.Printf("⚠️ Game manager shutdown: %v", err)
+	}
+
+	log.Printf("👋 Shutdown complete")
+	return serveErr
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+
+	pages "server/ui/pages/game"
+
+	"github.com/go-chi/chi/v5"
+	datastar "github.com/starfederation/datastar/sdk/go"
+)
+
+// spectators counts open spectator streams. Spectators never join a team.
+var spectators atomic.Int64
+
+// SetupSpectatorRoutes mounts the read-only /spectate page and its SSE stream
+func SetupSpectatorRoutes(router chi.Router) {
+	router.With(rateLimiter.Limit).Get("/spectate", func(w http.ResponseWriter, r *http.Request) {
+		gameState, err := natsGameManager.GetGameState()
+		if err != nil {
+			http.Error(w, "Failed to get game state", http.StatusInternalServerError)
+			return
+		}
+
+		pages.SpectatePage(gameState, int(spectators.Load()), requestPalette(r)).Render(r.Context(), w)
+	})
+
+	router.With(rateLimiter.Limit).Get("/api/spectate/sse", func(w http.ResponseWriter, r *http.Request) {
+		sub := broadcastHub.Subscribe()
+		defer broadcastHub.Unsubscribe(sub)
+
+		sse := datastar.NewSSE(w, r)
+
+		viewers := spectators.Add(1)
+		defer spectators.Add(-1)
+		log.Printf("👀 Spectator connected (%d watching)", viewers)
+
+		sentVersion, _ := strconv.ParseUint(r.Header.Get("Last-Event-ID"), 10, 64)
+
+		for {
+			select {
+			case <-r.Context().Done():
+				log.Printf("👀 Spectator disconnected")
+				return
+
+			case <-serverShutdown:
+				sse.MergeFragmentTempl(pages.ServerRestartingComponent())
+				return
+
+			case frame := <-sub.frames:
+				sendSharedFragments(sse, frame, sentVersion)
+
+				gameState := frame.state
+				eventID := strconv.FormatUint(frame.version, 10)
+				sse.MarshalAndMergeSignals(map[string]interface{}{
+					"viewers":    spectators.Load(),
+					"roundState": gameState.RoundState,
+					"roundTime":  int(gameState.RoundTimeRemaining.Seconds()),
+					"countdown":  int(gameState.Countdown.Seconds()),
+				}, datastar.WithMergeSignalsEventID(eventID))
+				sentVersion = frame.version
+			}
+		}
+	})
+}
@@ -1,6 +1,8 @@
 package types
 
 import (
+	"context"
+	"errors"
 	"sync"
 	"time"
 
@@ -20,6 +22,7 @@ const (
 	RoundDuration     = 3 * time.Minute
 	PostRoundDelay    = 10 * time.Second
 	PreRoundCountdown = 5 * time.Second
+	KickCooldown      = 2 * time.Minute // before a kicked player may rejoin
 )
 
 type RoundState string
@@ -56,6 +59,15 @@ type Cell struct {
 	Color   string `json:"color"`
 }
 
+// NeutralOwnerID and NeutralCellColor mark cells no team owns
+const (
+	NeutralOwnerID   = "neutral"
+	NeutralCellColor = "#374151"
+)
+
+// NeutralCell is the cell at every position no team owns
+var NeutralCell = Cell{OwnerID: NeutralOwnerID, Color: NeutralCellColor}
+
 type GameState struct {
 	Grid               *sync.Map        // [string]Cell, key is "x:y"
 	Teams              map[string]*Team // [string]*Team, key is teamID
@@ -63,6 +75,8 @@ type GameState struct {
 	RoundTimeRemaining time.Duration
 	Countdown          time.Duration
 	Winner             *Team
+	Paused             bool
+	RoundID            int
 }
 
 // NATS subject constants for pub/sub messaging
@@ -90,10 +104,22 @@ const (
 	SubjectTeamVoltcrash  = "game.team.Voltcrash"
 )
 
+// Sentinel errors returned by the game manager
+var (
+	ErrPlayerNotFound = errors.New("player not found")
+	ErrPlayerBanned   = errors.New("player is banned")
+	ErrPlayerKicked   = errors.New("player was kicked; try again later")
+	ErrShuttingDown   = errors.New("server is shutting down")
+
+	ErrTournamentInProgress = errors.New("only registered agents may join during a tournament")
+)
+
 // NATS stream and KV bucket names
 const (
 	StreamGameEvents = "GAME_EVENTS"
+	StreamMCPAudit   = "MCP_AUDIT"
 	KVGameState      = "game_state"
+	KVGameData       = "game_data"
 	KVPlayerSessions = "player_sessions"
 )
 
@@ -104,6 +130,9 @@ type NATSConfig struct {
 	MaxAge   time.Duration
 	MaxBytes int64
 	Replicas int
+
+	// InProcess opens no client port; the game manager connects in-process
+	InProcess bool
 }
 
 // DefaultNATSConfig returns a default NATS configuration
@@ -142,6 +171,8 @@ type GameStateSnapshot struct {
 	RoundTimeRemaining time.Duration    `json:"roundTimeRemaining"`
 	Countdown          time.Duration    `json:"countdown"`
 	Winner             *Team            `json:"winner,omitempty"`
+	Paused             bool             `json:"paused,omitempty"`
+	RoundID            int              `json:"roundId"`
 	Timestamp          int64            `json:"timestamp"`
 }
 
@@ -150,9 +181,14 @@ type NATSManager interface {
 	// Core operations
 	Start() error
 	Stop() error
+	Shutdown(ctx context.Context) error
+	Drain()
+	IsDraining() bool
 	GetNC() *nats.Conn
 	GetJS() jetstream.JetStream
 	GetKV() jetstream.KeyValue
+	Health() *HealthReport
+	Readiness(ctx context.Context) *HealthReport
 
 	// Game state management
 	GetGameState() (*GameState, error)
@@ -169,6 +205,50 @@ type NATSManager interface {
 
 	// Game actions
 	PlaceBit(playerID string, x, y int) (bool, error)
+	GetCooldownStatus(playerID string) (*CooldownStatus, error)
+
+	// Team chat
+	SetChatFilter(filter ChatFilter)
+	SendTeamChat(playerID, text string, emote bool) (*ChatMessage, error)
+	SubscribeTeamChat(teamID string, handler func(*ChatMessage)) (func(), error)
+	TeamChatHistory(ctx context.Context, teamID string, limit int) ([]*ChatMessage, error)
+
+	// Player preferences
+	GetPlayerPrefs(playerID string) (*PlayerPrefs, error)
+	SetPlayerPalette(playerID, paletteID string) error
+
+	// Server-side bots
+	SaveBotScript(script *BotScript) error
+	DeleteBotScript(playerID string) error
+	BotScripts(ctx context.Context) ([]*BotScript, error)
+
+	// MCP audit trail
+	RecordMCPCall(record *MCPCallRecord) error
+	MCPUsage(ctx context.Context, since time.Time) ([]*MCPUsage, error)
+
+	// Agent tournaments
+	RegisterAgent(playerID, name, teamID string) (*TournamentAgent, error)
+	RemoveAgent(playerID string) error
+	StartTournament(rounds int) error
+	StopTournament() error
+	GetTournament() (*Tournament, error)
+
+	// Round history
+	GetRound(roundID int) (*RoundRecord, error)
+	ReplayRound(ctx context.Context, roundID int, fn func(*RoundEvent) error) error
+
+	// Admin operations (each publishes an audit event to GAME_EVENTS)
+	PauseRound() error
+	ResumeRound() error
+	ForceStartRound() error
+	ForceEndRound() error
+	ResetGrid() error
+	AdjustRoundTime(delta time.Duration) error
+	KickPlayer(playerID string) error
+	Removed(playerID string) bool
+	BanPlayer(playerID string) error
+	UnbanPlayer(playerID string) error
+	GrantBits(playerID string, amount int) error
 
 	// Broadcasting
 	BroadcastGameState() error
@@ -0,0 +1,327 @@
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+)
+
+// kvKeyBans is the game_data KV key holding the list of banned player IDs
+const kvKeyBans = "bans"
+
+// Admin operations
+//
+// Every admin action mutates state under stateMu, persists it to KV and
+// publishes an "admin.<action>" audit event to the GAME_EVENTS stream.
+
+func (gm *NATSGameManager) PauseRound() error {
+	gm.stateMu.Lock()
+	defer gm.stateMu.Unlock()
+
+	if gm.state.Paused {
+		return fmt.Errorf("round is already paused")
+	}
+
+	gm.state.Paused = true
+	gm.saveGameStateToKV()
+	gm.publishAdminEvent("pause", map[string]interface{}{
+		"roundState": gm.state.RoundState,
+	})
+
+	log.Printf("⏸️ Admin paused the round")
+	return nil
+}
+
+func (gm *NATSGameManager) ResumeRound() error {
+	gm.stateMu.Lock()
+	defer gm.stateMu.Unlock()
+
+	if !gm.state.Paused {
+		return fmt.Errorf("round is not paused")
+	}
+
+	gm.state.Paused = false
+	gm.saveGameStateToKV()
+	gm.publishAdminEvent("resume", map[string]interface{}{
+		"roundState": gm.state.RoundState,
+	})
+
+	log.Printf("▶️ Admin resumed the round")
+	return nil
+}
+
+func (gm *NATSGameManager) ForceStartRound() error {
+	gm.stateMu.Lock()
+	defer gm.stateMu.Unlock()
+
+	if gm.state.RoundState == InProgress {
+		return fmt.Errorf("round is already in progress")
+	}
+
+	if gm.state.RoundState == Finished {
+		gm.resetGame()
+	}
+
+	previous := gm.state.RoundState
+	gm.state.RoundState = InProgress
+	gm.state.RoundTimeRemaining = RoundDuration
+	gm.state.Paused = false
+	gm.updateTeamPlayerCounts()
+
+	gm.startRound()
+	gm.saveGameStateToKV()
+	gm.publishAdminEvent("force_start", map[string]interface{}{
+		"previousState": previous,
+	})
+
+	log.Printf("🏁 Admin force-started the round")
+	return nil
+}
+
+func (gm *NATSGameManager) ForceEndRound() error {
+	gm.stateMu.Lock()
+	defer gm.stateMu.Unlock()
+
+	if gm.state.RoundState != InProgress {
+		return fmt.Errorf("no round in progress")
+	}
+
+	remaining := gm.state.RoundTimeRemaining
+	gm.state.RoundState = Finished
+	gm.state.Countdown = PostRoundDelay
+	gm.state.Paused = false
+	gm.determineWinner()
+
+	gm.finishRound()
+	gm.saveGameStateToKV()
+	gm.publishAdminEvent("force_end", map[string]interface{}{
+		"timeRemaining": remaining.Seconds(),
+	})
+
+	log.Printf("🏁 Admin force-ended the round")
+	return nil
+}
+
+func (gm *NATSGameManager) ResetGrid() error {
+	gm.stateMu.Lock()
+	defer gm.stateMu.Unlock()
+
+	gm.initGrid()
+	for _, team := range gm.state.Teams {
+		team.Score = 0
+		team.Percentage = 0
+	}
+
+	gm.saveGameStateToKV()
+	gm.publishAdminEvent("reset_grid", map[string]interface{}{
+		"roundState": gm.state.RoundState,
+	})
+
+	log.Printf("🧹 Admin reset the grid")
+	return nil
+}
+
+// AdjustRoundTime shifts the active timer by delta. During a round this is the
+// time remaining; between rounds it is the countdown to the next transition.
+func (gm *NATSGameManager) AdjustRoundTime(delta time.Duration) error {
+	gm.stateMu.Lock()
+	defer gm.stateMu.Unlock()
+
+	timer := &gm.state.Countdown
+	if gm.state.RoundState == InProgress {
+		timer = &gm.state.RoundTimeRemaining
+	}
+
+	*timer += delta
+	if *timer < 0 {
+		*timer = 0
+	}
+
+	gm.saveGameStateToKV()
+	gm.publishAdminEvent("adjust_time", map[string]interface{}{
+		"roundState": gm.state.RoundState,
+		"delta":      delta.Seconds(),
+		"remaining":  timer.Seconds(),
+	})
+
+	log.Printf("⏱️ Admin adjusted the %s timer by %v (now %v)", gm.state.RoundState, delta, *timer)
+	return nil
+}
+
+// KickPlayer removes the player from their team and keeps them from
+// rejoining for KickCooldown
+func (gm *NATSGameManager) KickPlayer(playerID string) error {
+	gm.stateMu.Lock()
+	defer gm.stateMu.Unlock()
+
+	p, team := gm.getPlayerLocked(playerID)
+	if p == nil {
+		return fmt.Errorf("%w: %s", ErrPlayerNotFound, playerID)
+	}
+
+	team.Players.Delete(playerID)
+	gm.updateTeamPlayerCounts()
+	now := time.Now()
+	for id, until := range gm.kicked {
+		if now.After(until) {
+			delete(gm.kicked, id)
+		}
+	}
+	gm.kicked[playerID] = now.Add(KickCooldown)
+
+	gm.saveGameStateToKV()
+	gm.publishAdminEvent("kick", map[string]interface{}{
+		"playerId": playerID,
+		"teamId":   team.ID,
+	})
+
+	log.Printf("👢 Admin kicked player %s from team %s", playerID, team.ID)
+	return nil
+}
+
+// Removed reports whether the player is banned, or was kicked and may not
+// rejoin yet. Their open streams should close.
+func (gm *NATSGameManager) Removed(playerID string) bool {
+	gm.stateMu.RLock()
+	defer gm.stateMu.RUnlock()
+	until, ok := gm.kicked[playerID]
+	return gm.banned[playerID] || ok && time.Now().Before(until)
+}
+
+// kickedLocked reports an active kick with stateMu write-locked; it forgets
+// expired kicks
+func (gm *NATSGameManager) kickedLocked(playerID string) bool {
+	until, ok := gm.kicked[playerID]
+	if ok && time.Now().After(until) {
+		delete(gm.kicked, playerID)
+		return false
+	}
+	return ok
+}
+
+// BanPlayer removes the player from their team (if present) and prevents the
+// ID from joining again until it is unbanned.
+func (gm *NATSGameManager) BanPlayer(playerID string) error {
+	gm.stateMu.Lock()
+	defer gm.stateMu.Unlock()
+
+	if gm.banned[playerID] {
+		return fmt.Errorf("player %s is already banned", playerID)
+	}
+
+	teamID := ""
+	if p, team := gm.getPlayerLocked(playerID); p != nil {
+		team.Players.Delete(playerID)
+		gm.updateTeamPlayerCounts()
+		teamID = team.ID
+	}
+
+	gm.banned[playerID] = true
+	if err := gm.saveBansToKV(); err != nil {
+		return fmt.Errorf("failed to save ban list: %w", err)
+	}
+
+	gm.saveGameStateToKV()
+	gm.publishAdminEvent("ban", map[string]interface{}{
+		"playerId": playerID,
+		"teamId":   teamID,
+	})
+
+	log.Printf("🔨 Admin banned player %s", playerID)
+	return nil
+}
+
+func (gm *NATSGameManager) UnbanPlayer(playerID string) error {
+	gm.stateMu.Lock()
+	defer gm.stateMu.Unlock()
+
+	if !gm.banned[playerID] {
+		return fmt.Errorf("player %s is not banned", playerID)
+	}
+
+	delete(gm.banned, playerID)
+	if err := gm.saveBansToKV(); err != nil {
+		return fmt.Errorf("failed to save ban list: %w", err)
+	}
+
+	gm.publishAdminEvent("unban", map[string]interface{}{
+		"playerId": playerID,
+	})
+
+	log.Printf("🕊️ Admin unbanned player %s", playerID)
+	return nil
+}
+
+// GrantBits adds bits to a player's balance, capped at MaxBits.
+func (gm *NATSGameManager) GrantBits(playerID string, amount int) error {
+	if amount <= 0 {
+		return fmt.Errorf("amount must be positive")
+	}
+
+	gm.stateMu.Lock()
+	defer gm.stateMu.Unlock()
+
+	p, team := gm.getPlayerLocked(playerID)
+	if p == nil {
+		return fmt.Errorf("%w: %s", ErrPlayerNotFound, playerID)
+	}
+
+	p.Bits += amount
+	if p.Bits > MaxBits {
+		p.Bits = MaxBits
+	}
+
+	gm.saveGameStateToKV()
+	gm.publishAdminEvent("grant_bits", map[string]interface{}{
+		"playerId": playerID,
+		"teamId":   team.ID,
+		"amount":   amount,
+		"bits":     p.Bits,
+	})
+
+	log.Printf("🎁 Admin granted %d bits to player %s (now %d)", amount, playerID, p.Bits)
+	return nil
+}
+
+// publishAdminEvent records an admin action on the game.admin.<action> subject
+func (gm *NATSGameManager) publishAdminEvent(action string, data map[string]interface{}) {
+	if err := gm.PublishGameEvent("admin."+action, data); err != nil {
+		log.Printf("❌ Failed to publish admin %s audit event: %v", action, err)
+	}
+}
+
+func (gm *NATSGameManager) saveBansToKV() error {
+	ids := make([]string, 0, len(gm.banned))
+	for id := range gm.banned {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	data, err := json.Marshal(ids)
+	if err != nil {
+		return err
+	}
+
+	_, err = gm.data.Put(gm.ctx, kvKeyBans, data)
+	return err
+}
+
+func (gm *NATSGameManager) loadBansFromKV() (map[string]bool, error) {
+	entry, err := gm.data.Get(gm.ctx, kvKeyBans)
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []string
+	if err := json.Unmarshal(entry.Value(), &ids); err != nil {
+		return nil, err
+	}
+
+	banned := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		banned[id] = true
+	}
+	return banned, nil
+}
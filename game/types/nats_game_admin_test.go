@@ -0,0 +1,64 @@
+package types
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRemoved(t *testing.T) {
+	gm := newTestManager(t)
+
+	for _, id := range []string{"kicked", "banned", "stale"} {
+		if _, err := gm.AddPlayer(id); err != nil {
+			t.Fatalf("AddPlayer(%s): %v", id, err)
+		}
+	}
+
+	if err := gm.KickPlayer("kicked"); err != nil {
+		t.Fatalf("KickPlayer: %v", err)
+	}
+	if err := gm.BanPlayer("banned"); err != nil {
+		t.Fatalf("BanPlayer: %v", err)
+	}
+	for _, id := range []string{"kicked", "banned"} {
+		if !gm.Removed(id) {
+			t.Errorf("Removed(%s) = false, want true", id)
+		}
+	}
+	if gm.Removed("stale") {
+		t.Error("Removed(stale) = true before any kick")
+	}
+
+	if _, err := gm.AddPlayer("kicked"); !errors.Is(err, ErrPlayerKicked) {
+		t.Errorf("AddPlayer(kicked) = %v, want ErrPlayerKicked", err)
+	}
+	if _, err := gm.AddPlayer("banned"); !errors.Is(err, ErrPlayerBanned) {
+		t.Errorf("AddPlayer(banned) = %v, want ErrPlayerBanned", err)
+	}
+
+	if err := gm.UnbanPlayer("banned"); err != nil {
+		t.Fatalf("UnbanPlayer: %v", err)
+	}
+	if gm.Removed("banned") {
+		t.Error("Removed(banned) = true after unban")
+	}
+
+	// An expired kick no longer counts and is forgotten by the next kick
+	gm.stateMu.Lock()
+	gm.kicked["kicked"] = time.Now().Add(-time.Second)
+	gm.stateMu.Unlock()
+	if gm.Removed("kicked") {
+		t.Error("Removed(kicked) = true after the cool-off")
+	}
+
+	if err := gm.KickPlayer("stale"); err != nil {
+		t.Fatalf("KickPlayer: %v", err)
+	}
+	gm.stateMu.RLock()
+	_, remembered := gm.kicked["kicked"]
+	gm.stateMu.RUnlock()
+	if remembered {
+		t.Error("expired kick was not forgotten")
+	}
+}
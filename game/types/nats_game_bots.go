@@ -0,0 +1,78 @@
+package types
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// kvKeyBotPrefix prefixes the game_data KV keys holding bot scripts
+const kvKeyBotPrefix = "bot."
+
+// Bot kinds
+const (
+	BotKindJS   = "js"
+	BotKindWASM = "wasm"
+)
+
+// BotScript is the server-side bot a player runs. It is kept in KV so bots
+// are restored after a restart.
+type BotScript struct {
+	PlayerID  string    `json:"playerId"`
+	Kind      string    `json:"kind,omitempty"` // BotKindJS when empty
+	Source    string    `json:"source,omitempty"`
+	Module    []byte    `json:"module,omitempty"` // WebAssembly binary
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// SaveBotScript stores a player's bot, replacing any previous one
+func (gm *NATSGameManager) SaveBotScript(script *BotScript) error {
+	data, err := json.Marshal(script)
+	if err != nil {
+		return fmt.Errorf("failed to marshal bot script: %w", err)
+	}
+	if _, err := gm.data.Put(gm.ctx, kvKeyBotPrefix+script.PlayerID, data); err != nil {
+		return fmt.Errorf("failed to save bot script: %w", err)
+	}
+	return nil
+}
+
+// DeleteBotScript removes a player's bot
+func (gm *NATSGameManager) DeleteBotScript(playerID string) error {
+	err := gm.data.Delete(gm.ctx, kvKeyBotPrefix+playerID)
+	if err != nil && !errors.Is(err, jetstream.ErrKeyNotFound) {
+		return fmt.Errorf("failed to delete bot script: %w", err)
+	}
+	return nil
+}
+
+// BotScripts returns every stored bot
+func (gm *NATSGameManager) BotScripts(ctx context.Context) ([]*BotScript, error) {
+	lister, err := gm.data.ListKeysFiltered(ctx, kvKeyBotPrefix+"*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list bot scripts: %w", err)
+	}
+	defer lister.Stop()
+
+	var scripts []*BotScript
+	for key := range lister.Keys() {
+		entry, err := gm.data.Get(ctx, key)
+		if errors.Is(err, jetstream.ErrKeyNotFound) {
+			continue
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to load bot script %s: %w", strings.TrimPrefix(key, kvKeyBotPrefix), err)
+		}
+
+		var script BotScript
+		if err := json.Unmarshal(entry.Value(), &script); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal bot script %s: %w", strings.TrimPrefix(key, kvKeyBotPrefix), err)
+		}
+		scripts = append(scripts, &script)
+	}
+	return scripts, nil
+}
@@ -0,0 +1,208 @@
+package types
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// Team chat limits
+const (
+	ChatMessageMaxLength = 200              // runes
+	ChatMessageTTL       = 15 * time.Minute // how long messages stay in JetStream
+	ChatHistorySize      = 30               // messages shown on (re)connect
+)
+
+// ChatEmotes is the quick-emote palette shown under the chat input
+var ChatEmotes = []string{"👍", "⚔️", "🛡️", "🎯", "🔥", "🆘", "👀", "🎉"}
+
+var (
+	ErrChatMessageEmpty   = errors.New("message is empty")
+	ErrChatMessageTooLong = fmt.Errorf("message is longer than %d characters", ChatMessageMaxLength)
+)
+
+// ChatMessage is a team chat message. It travels inside a GameEventMessage of
+// type "team_chat" on the team's subject.
+type ChatMessage struct {
+	TeamID    string `json:"teamId"`
+	PlayerID  string `json:"playerId"`
+	Text      string `json:"text"`
+	Emote     bool   `json:"emote,omitempty"`
+	Timestamp int64  `json:"timestamp"`
+}
+
+// ChatFilter inspects a message before it is published. It returns the text
+// to send, possibly rewritten, or an error to reject the message.
+type ChatFilter func(playerID, text string) (string, error)
+
+// NewWordListChatFilter masks each listed word (case-insensitive, whole
+// words) with asterisks
+func NewWordListChatFilter(words []string) ChatFilter {
+	var quoted []string
+	for _, w := range words {
+		if w = strings.TrimSpace(w); w != "" {
+			quoted = append(quoted, regexp.QuoteMeta(w))
+		}
+	}
+	if len(quoted) == 0 {
+		return nil
+	}
+
+	pattern := regexp.MustCompile(`(?i)\b(` + strings.Join(quoted, "|") + `)\b`)
+	return func(playerID, text string) (string, error) {
+		return pattern.ReplaceAllStringFunc(text, func(match string) string {
+			return strings.Repeat("*", utf8.RuneCountInString(match))
+		}), nil
+	}
+}
+
+// TeamSubject returns the subject team chat is published on, e.g.
+// SubjectTeamGlitchbyte
+func TeamSubject(teamID string) string {
+	return "game.team." + teamID
+}
+
+// SetChatFilter installs the hook every chat message passes through
+func (gm *NATSGameManager) SetChatFilter(filter ChatFilter) {
+	gm.chatFilter.Store(&filter)
+}
+
+// SendTeamChat validates, filters and publishes a message to the player's
+// team. emote marks messages sent from the emote palette.
+func (gm *NATSGameManager) SendTeamChat(playerID, text string, emote bool) (*ChatMessage, error) {
+	if gm.draining.Load() {
+		return nil, ErrShuttingDown
+	}
+
+	player, team := gm.GetPlayer(playerID)
+	if player == nil || team == nil {
+		return nil, ErrPlayerNotFound
+	}
+
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil, ErrChatMessageEmpty
+	}
+	if utf8.RuneCountInString(text) > ChatMessageMaxLength {
+		return nil, ErrChatMessageTooLong
+	}
+
+	if filter := gm.chatFilter.Load(); filter != nil && *filter != nil {
+		filtered, err := (*filter)(playerID, text)
+		if err != nil {
+			return nil, err
+		}
+		text = filtered
+	}
+
+	message := &ChatMessage{
+		TeamID:    team.ID,
+		PlayerID:  playerID,
+		Text:      text,
+		Emote:     emote,
+		Timestamp: time.Now().UnixMilli(),
+	}
+
+	data, err := json.Marshal(&GameEventMessage{
+		Type:      "team_chat",
+		PlayerID:  playerID,
+		TeamID:    team.ID,
+		Data:      message,
+		Timestamp: message.Timestamp,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal chat message: %w", err)
+	}
+
+	if _, err := gm.js.Publish(gm.ctx, TeamSubject(team.ID), data, jetstream.WithMsgTTL(ChatMessageTTL)); err != nil {
+		return nil, fmt.Errorf("failed to publish chat message: %w", err)
+	}
+
+	return message, nil
+}
+
+// SubscribeTeamChat calls handler for each new message to teamID until the
+// returned function is called
+func (gm *NATSGameManager) SubscribeTeamChat(teamID string, handler func(*ChatMessage)) (func(), error) {
+	sub, err := gm.nc.Subscribe(TeamSubject(teamID), func(msg *nats.Msg) {
+		if message, err := decodeChatMessage(msg.Data); err == nil {
+			handler(message)
+		}
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to subscribe to team chat: %w", err)
+	}
+
+	return func() { sub.Unsubscribe() }, nil
+}
+
+// TeamChatHistory returns up to limit recent messages for teamID, oldest first
+func (gm *NATSGameManager) TeamChatHistory(ctx context.Context, teamID string, limit int) ([]*ChatMessage, error) {
+	consumer, err := gm.js.OrderedConsumer(ctx, StreamGameEvents, jetstream.OrderedConsumerConfig{
+		FilterSubjects: []string{TeamSubject(teamID)},
+		DeliverPolicy:  jetstream.DeliverByStartTimePolicy,
+		OptStartTime:   ptr(time.Now().Add(-ChatMessageTTL)),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create chat history consumer: %w", err)
+	}
+
+	var messages []*ChatMessage
+	for {
+		batch, err := consumer.FetchNoWait(100)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch chat history: %w", err)
+		}
+
+		received := 0
+		pending := uint64(0)
+		for msg := range batch.Messages() {
+			received++
+			if meta, err := msg.Metadata(); err == nil {
+				pending = meta.NumPending
+			}
+			message, err := decodeChatMessage(msg.Data())
+			if err != nil {
+				log.Printf("⚠️ Skipping undecodable chat message: %v", err)
+				continue
+			}
+			messages = append(messages, message)
+			if len(messages) > limit {
+				messages = messages[1:]
+			}
+		}
+		if err := batch.Error(); err != nil {
+			return nil, fmt.Errorf("failed to fetch chat history: %w", err)
+		}
+		if received == 0 || pending == 0 {
+			return messages, nil
+		}
+	}
+}
+
+func decodeChatMessage(data []byte) (*ChatMessage, error) {
+	var event struct {
+		Type string      `json:"type"`
+		Data ChatMessage `json:"data"`
+	}
+	if err := json.Unmarshal(data, &event); err != nil {
+		return nil, err
+	}
+	if event.Type != "team_chat" {
+		return nil, fmt.Errorf("unexpected event type %q", event.Type)
+	}
+	return &event.Data, nil
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
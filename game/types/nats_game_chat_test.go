@@ -0,0 +1,125 @@
+package types
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestNewWordListChatFilter(t *testing.T) {
+	tests := []struct {
+		name  string
+		words []string
+		text  string
+		want  string
+	}{
+		{"masks a word", []string{"darn"}, "well darn it", "well **** it"},
+		{"ignores case", []string{"darn"}, "DARN and Darn", "**** and ****"},
+		{"whole words only", []string{"ass"}, "class assembly ass", "class assembly ***"},
+		{"several words", []string{"darn", " heck "}, "heck, darn!", "****, ****!"},
+		{"quotes patterns", []string{"a.b"}, "axb a.b", "axb ***"},
+		{"no match", []string{"darn"}, "all good", "all good"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewWordListChatFilter(tt.words)("player", tt.text)
+			if err != nil {
+				t.Fatalf("filter: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("filter(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+
+	for _, words := range [][]string{nil, {}, {" ", ""}} {
+		if filter := NewWordListChatFilter(words); filter != nil {
+			t.Errorf("NewWordListChatFilter(%q) returned a filter", words)
+		}
+	}
+}
+
+func TestSendTeamChat(t *testing.T) {
+	gm := newTestManager(t)
+	gm.SetChatFilter(NewWordListChatFilter([]string{"darn"}))
+	if _, err := gm.AddPlayer("p1"); err != nil {
+		t.Fatalf("AddPlayer: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		player  string
+		text    string
+		want    string
+		wantErr error
+	}{
+		{"filtered", "p1", "  darn it  ", "**** it", nil},
+		{"empty", "p1", "   ", "", ErrChatMessageEmpty},
+		{"too long", "p1", strings.Repeat("a", ChatMessageMaxLength+1), "", ErrChatMessageTooLong},
+		{"longest", "p1", strings.Repeat("é", ChatMessageMaxLength), strings.Repeat("é", ChatMessageMaxLength), nil},
+		{"unknown player", "nobody", "hi", "", ErrPlayerNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			message, err := gm.SendTeamChat(tt.player, tt.text, false)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("SendTeamChat error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && message.Text != tt.want {
+				t.Errorf("text = %q, want %q", message.Text, tt.want)
+			}
+		})
+	}
+}
+
+func TestTeamChatHistory(t *testing.T) {
+	gm := newTestManager(t)
+	player, err := gm.AddPlayer("p1")
+	if err != nil {
+		t.Fatalf("AddPlayer: %v", err)
+	}
+	for i := 1; i <= 5; i++ {
+		if _, err := gm.SendTeamChat("p1", fmt.Sprintf("message %d", i), false); err != nil {
+			t.Fatalf("SendTeamChat: %v", err)
+		}
+	}
+
+	var otherTeam string
+	gm.stateMu.RLock()
+	for teamID := range gm.state.Teams {
+		if teamID != player.TeamID {
+			otherTeam = teamID
+			break
+		}
+	}
+	gm.stateMu.RUnlock()
+
+	tests := []struct {
+		name   string
+		teamID string
+		limit  int
+		want   []string
+	}{
+		{"keeps the latest", player.TeamID, 3, []string{"message 3", "message 4", "message 5"}},
+		{"limit above count", player.TeamID, 10, []string{"message 1", "message 2", "message 3", "message 4", "message 5"}},
+		{"limit of one", player.TeamID, 1, []string{"message 5"}},
+		{"other team", otherTeam, 10, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			messages, err := gm.TeamChatHistory(context.Background(), tt.teamID, tt.limit)
+			if err != nil {
+				t.Fatalf("TeamChatHistory: %v", err)
+			}
+			var got []string
+			for _, message := range messages {
+				got = append(got, message.Text)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("history = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package types
+
+import (
+	"fmt"
+	"time"
+)
+
+// CooldownStatus tells a player when they can act next
+type CooldownStatus struct {
+	PlayerID     string        `json:"playerId"`
+	Bits         int           `json:"bits"`
+	MaxBits      int           `json:"maxBits"`
+	CanPlace     bool          `json:"canPlace"`
+	NextActionIn time.Duration `json:"nextActionIn"` // until the action cooldown ends
+	// NextBitIn is the time until the next bit regenerates, or -1 when no bit
+	// is coming: the player is full or no round is running
+	NextBitIn time.Duration `json:"nextBitIn"`
+}
+
+// GetCooldownStatus reports a player's bits, action cooldown and the time
+// until the next bit regenerates
+func (gm *NATSGameManager) GetCooldownStatus(playerID string) (*CooldownStatus, error) {
+	gm.stateMu.RLock()
+	defer gm.stateMu.RUnlock()
+
+	player, _ := gm.getPlayerLocked(playerID)
+	if player == nil {
+		return nil, fmt.Errorf("%w: %s", ErrPlayerNotFound, playerID)
+	}
+
+	now := time.Now()
+	status := &CooldownStatus{
+		PlayerID:     player.ID,
+		Bits:         player.Bits,
+		MaxBits:      MaxBits,
+		NextActionIn: max(player.LastAction.Add(ActionCooldown).Sub(now), 0),
+		NextBitIn:    -1,
+	}
+
+	running := gm.state.RoundState == InProgress && !gm.state.Paused
+	if running && player.Bits < MaxBits {
+		nextTick := time.Unix(0, gm.lastBitsTick.Load()).Add(GameTickRate)
+		status.NextBitIn = max(nextTick.Sub(now), 0)
+	}
+	status.CanPlace = running && player.Bits > 0 && status.NextActionIn == 0
+	return status, nil
+}
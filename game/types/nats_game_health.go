@@ -0,0 +1,109 @@
+package types
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// kvKeyHealth is the game_state KV key written by readiness probes
+const kvKeyHealth = "healthcheck"
+
+// GameLoopStallThreshold is how long the game loop may go without a tick
+// before it is reported as stalled
+const GameLoopStallThreshold = 5 * time.Second
+
+// HealthReport describes the state of the manager's dependencies
+type HealthReport struct {
+	NATSServerRunning bool          `json:"natsServerRunning"`
+	NATSConnected     bool          `json:"natsConnected"`
+	NATSStatus        string        `json:"natsStatus"`
+	Stores            *StoreHealth  `json:"stores,omitempty"`
+	LastTick          time.Time     `json:"lastTick"`
+	SinceLastTick     time.Duration `json:"-"`
+	SinceLastTickMs   float64       `json:"sinceLastTickMs"`
+	GameLoopStalled   bool          `json:"gameLoopStalled"`
+	Draining          bool          `json:"draining"`
+}
+
+// StoreHealth is the result of the JetStream and KV probes of a readiness check
+type StoreHealth struct {
+	JetStreamOK    bool    `json:"jetStreamOk"`
+	JetStreamError string  `json:"jetStreamError,omitempty"`
+	KVOK           bool    `json:"kvOk"`
+	KVError        string  `json:"kvError,omitempty"`
+	KVRoundTripMs  float64 `json:"kvRoundTripMs"`
+}
+
+// Health reports the state of the process: the embedded NATS server, the
+// connection to it and game loop liveness. It makes no requests.
+func (gm *NATSGameManager) Health() *HealthReport {
+	report := &HealthReport{
+		NATSServerRunning: gm.ns != nil && gm.ns.NatsServer.Running(),
+		NATSConnected:     gm.nc != nil && gm.nc.IsConnected(),
+		Draining:          gm.IsDraining(),
+	}
+	if gm.nc != nil {
+		report.NATSStatus = gm.nc.Status().String()
+	}
+
+	if lastTick := gm.lastTick.Load(); lastTick > 0 {
+		report.LastTick = time.Unix(0, lastTick)
+		report.SinceLastTick = time.Since(report.LastTick)
+		report.SinceLastTickMs = milliseconds(report.SinceLastTick)
+	}
+	report.GameLoopStalled = report.LastTick.IsZero() || report.SinceLastTick > GameLoopStallThreshold
+	return report
+}
+
+// Readiness extends Health with probes of JetStream and the KV bucket. The KV
+// probe writes and reads back a timestamp.
+func (gm *NATSGameManager) Readiness(ctx context.Context) *HealthReport {
+	report := gm.Health()
+	stores := &StoreHealth{}
+	report.Stores = stores
+
+	if _, err := gm.js.AccountInfo(ctx); err != nil {
+		stores.JetStreamError = err.Error()
+	} else {
+		stores.JetStreamOK = true
+	}
+
+	start := time.Now()
+	if err := gm.probeKV(ctx, start); err != nil {
+		stores.KVError = err.Error()
+	} else {
+		stores.KVOK = true
+	}
+	stores.KVRoundTripMs = milliseconds(time.Since(start))
+
+	return report
+}
+
+// probeKV writes now to the health key and reads the key back. A concurrent
+// probe may have overwritten it since, so a later revision also passes; only
+// the revision written here must hold the value written here.
+func (gm *NATSGameManager) probeKV(ctx context.Context, now time.Time) error {
+	want := strconv.FormatInt(now.UnixNano(), 10)
+	revision, err := gm.kv.PutString(ctx, kvKeyHealth, want)
+	if err != nil {
+		return fmt.Errorf("failed to write health key: %w", err)
+	}
+
+	entry, err := gm.kv.Get(ctx, kvKeyHealth)
+	if err != nil {
+		return fmt.Errorf("failed to read health key: %w", err)
+	}
+	switch {
+	case entry.Revision() < revision:
+		return fmt.Errorf("health key is stale: wrote revision %d, read %d", revision, entry.Revision())
+	case entry.Revision() == revision && string(entry.Value()) != want:
+		return fmt.Errorf("health key mismatch: wrote %s, read %s", want, string(entry.Value()))
+	}
+	return nil
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
@@ -7,6 +7,7 @@ import (
 	"log"
 	"sort"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/delaneyj/toolbelt/embeddednats"
@@ -23,6 +24,9 @@ type NATSGameManager struct {
 	js jetstream.JetStream
 	kv jetstream.KeyValue
 
+	// Data that must outlive the game state; unlike kv it has no TTL
+	data jetstream.KeyValue
+
 	// Game configuration
 	config *NATSConfig
 	ctx    context.Context
@@ -31,9 +35,27 @@ type NATSGameManager struct {
 	// Local state cache (for performance)
 	stateMu sync.RWMutex
 	state   *GameState
+	banned  map[string]bool      // guarded by stateMu
+	kicked  map[string]time.Time // end of each kick's cool-off, guarded by stateMu
+
+	// Agent tournament, guarded by stateMu
+	tournament *tournamentState
+
+	// Round in progress, guarded by stateMu
+	currentRound *RoundRecord
 
 	// Game loop management
-	gameLoopDone chan struct{}
+	gameLoopDone   chan struct{}
+	gameLoopExited chan struct{}
+	lastTick       atomic.Int64 // unix nanos of the last game tick
+	lastBitsTick   atomic.Int64 // unix nanos of the last bit regeneration tick
+
+	// Team chat moderation hook
+	chatFilter atomic.Pointer[ChatFilter]
+
+	// Shutdown management
+	draining atomic.Bool
+	stopOnce sync.Once
 
 	// Event handlers
 	eventSubscriptions []*nats.Subscription
@@ -46,8 +68,12 @@ func NewNATSGameManager(ctx context.Context, config *NATSConfig) (*NATSGameManag
 	}
 
 	gm := &NATSGameManager{
-		config:       config,
-		gameLoopDone: make(chan struct{}),
+		config:         config,
+		banned:         make(map[string]bool),
+		kicked:         make(map[string]time.Time),
+		tournament:     newTournamentState(),
+		gameLoopDone:   make(chan struct{}),
+		gameLoopExited: make(chan struct{}),
 	}
 
 	gm.ctx, gm.cancel = context.WithCancel(ctx)
@@ -74,8 +100,9 @@ func (gm *NATSGameManager) initNATS() error {
 	natsOptions := &server.Options{
 		JetStream: true,
 		Port:      gm.config.Port,
-		StoreDir:  gm.config.DataDir,
-		NoSigs:    true,
+		StoreDir:   gm.config.DataDir,
+		NoSigs:     true,
+		DontListen: gm.config.InProcess,
 	}
 
 	gm.ns, err = embeddednats.New(gm.ctx, embeddednats.WithNATSServerOptions(natsOptions))
@@ -84,10 +111,15 @@ func (gm *NATSGameManager) initNATS() error {
 	}
 
 	gm.ns.WaitForServer()
-	log.Printf("🚀 NATS server started on %s", gm.ns.NatsServer.ClientURL())
 
 	// Connect to NATS
-	gm.nc, err = gm.ns.Client()
+	if gm.config.InProcess {
+		log.Printf("🚀 NATS server started in-process")
+		gm.nc, err = nats.Connect("", nats.InProcessServer(gm.ns.NatsServer))
+	} else {
+		log.Printf("🚀 NATS server started on %s", gm.ns.NatsServer.ClientURL())
+		gm.nc, err = gm.ns.Client()
+	}
 	if err != nil {
 		return fmt.Errorf("failed to connect to NATS: %w", err)
 	}
@@ -110,6 +142,17 @@ func (gm *NATSGameManager) initNATS() error {
 		return fmt.Errorf("failed to create KV store: %w", err)
 	}
 
+	// Create KV store for data that must outlive the game state TTL
+	gm.data, err = gm.js.CreateOrUpdateKeyValue(gm.ctx, jetstream.KeyValueConfig{
+		Bucket:      KVGameData,
+		Description: "BitSplat Game Data",
+		Compression: true,
+		MaxBytes:    gm.config.MaxBytes,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create data KV store: %w", err)
+	}
+
 	// Create game events stream
 	_, err = gm.js.CreateOrUpdateStream(gm.ctx, jetstream.StreamConfig{
 		Name:        StreamGameEvents,
@@ -119,11 +162,26 @@ func (gm *NATSGameManager) initNATS() error {
 		MaxAge:      gm.config.MaxAge,
 		MaxBytes:    gm.config.MaxBytes,
 		Replicas:    gm.config.Replicas,
+		AllowMsgTTL: true, // team chat messages expire after ChatMessageTTL
 	})
 	if err != nil {
 		return fmt.Errorf("failed to create game events stream: %w", err)
 	}
 
+	// Create MCP audit stream
+	_, err = gm.js.CreateOrUpdateStream(gm.ctx, jetstream.StreamConfig{
+		Name:        StreamMCPAudit,
+		Description: "BitSplat MCP tool calls",
+		Subjects:    []string{"mcp.audit.>"},
+		Retention:   jetstream.LimitsPolicy,
+		MaxAge:      gm.config.MaxAge,
+		MaxBytes:    gm.config.MaxBytes,
+		Replicas:    gm.config.Replicas,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create MCP audit stream: %w", err)
+	}
+
 	log.Printf("📦 NATS JetStream and KV store initialized")
 	return nil
 }
@@ -158,23 +216,43 @@ func (gm *NATSGameManager) initGameState() error {
 		log.Printf("🎯 Created new game state with %d teams", len(gm.state.Teams))
 	}
 
+	// Resume recording a round that was in progress
+	if gm.state.RoundState == InProgress && gm.state.RoundID > 0 {
+		if record, err := gm.loadRoundRecord(gm.state.RoundID); err == nil && !record.Finished() {
+			gm.currentRound = record
+		}
+	}
+
+	// Restore the ban list
+	if banned, err := gm.loadBansFromKV(); err == nil {
+		gm.banned = banned
+		log.Printf("🔨 Loaded %d banned players", len(banned))
+	}
+
+	// Restore the tournament
+	if tournament, err := gm.loadTournamentFromKV(); err == nil {
+		gm.tournament = tournament
+		log.Printf("🏟️ Loaded tournament with %d agents (active: %v)", len(tournament.Agents), tournament.Active)
+	}
+
 	return nil
 }
 
 // initTeams initializes the game teams
 func (gm *NATSGameManager) initTeams() {
-	teamColors := []string{"#d500f9", "#00bcd4", "#e91e63", "#76ff03"}
-	teamNames := []string{"Glitchbyte", "Nullwave", "Overburn", "Voltcrash"}
+	// Team colors stored in game state always come from the default palette;
+	// other palettes are applied client-side through generated CSS
+	colors := Palettes[0].Colors
 
-	for i, name := range teamNames {
+	for _, name := range TeamIDs {
 		gm.state.Teams[name] = &Team{
 			ID:         name,
-			Color:      teamColors[i],
+			Color:      colors[name],
 			Score:      0,
 			Players:    new(sync.Map),
 			Percentage: 0,
 		}
-		log.Printf("🚩 Team %s created with color %s", name, teamColors[i])
+		log.Printf("🚩 Team %s created with color %s", name, colors[name])
 	}
 }
 
@@ -183,7 +261,7 @@ func (gm *NATSGameManager) initGrid() {
 	for y := 0; y < GridHeight; y++ {
 		for x := 0; x < GridWidth; x++ {
 			key := fmt.Sprintf("%d:%d", x, y)
-			gm.state.Grid.Store(key, Cell{OwnerID: "neutral", Color: "#374151"})
+			gm.state.Grid.Store(key, NeutralCell)
 		}
 	}
 	gm.state.Winner = nil
@@ -206,26 +284,94 @@ func (gm *NATSGameManager) Start() error {
 }
 
 func (gm *NATSGameManager) Stop() error {
-	// Stop game loop
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	return gm.Shutdown(ctx)
+}
+
+// Shutdown stops accepting actions, stops the game loop, flushes the final
+// state to KV and shuts NATS down, giving up on each wait when ctx expires.
+// It is safe to call more than once.
+func (gm *NATSGameManager) Shutdown(ctx context.Context) error {
+	var err error
+	gm.stopOnce.Do(func() {
+		err = gm.shutdown(ctx)
+	})
+	return err
+}
+
+func (gm *NATSGameManager) shutdown(ctx context.Context) error {
+	gm.Drain()
+
+	// Stop game loop and wait for the current tick to finish
 	close(gm.gameLoopDone)
+	select {
+	case <-gm.gameLoopExited:
+	case <-ctx.Done():
+		log.Printf("⚠️ Game loop did not stop before the shutdown deadline")
+	}
+
+	// Flush the final state
+	gm.stateMu.Lock()
+	flushErr := gm.saveGameStateToKV()
+	gm.stateMu.Unlock()
+	if flushErr != nil {
+		log.Printf("❌ Failed to flush final game state: %v", flushErr)
+	} else {
+		log.Printf("💾 Final game state flushed to KV")
+	}
 
 	// Unsubscribe from events
 	for _, sub := range gm.eventSubscriptions {
 		sub.Unsubscribe()
 	}
 
-	// Close NATS connections
+	// Drain the connection so in-flight publishes reach JetStream
 	if gm.nc != nil {
-		gm.nc.Close()
+		closed := make(chan struct{})
+		gm.nc.SetClosedHandler(func(*nats.Conn) { close(closed) })
+		if err := gm.nc.Drain(); err != nil {
+			gm.nc.Close()
+		} else {
+			select {
+			case <-closed:
+			case <-ctx.Done():
+				log.Printf("⚠️ NATS connection did not drain before the shutdown deadline")
+				gm.nc.Close()
+			}
+		}
 	}
 
+	// Shut down the embedded server
 	if gm.ns != nil {
-		gm.ns.Close()
+		gm.ns.NatsServer.Shutdown()
+		stopped := make(chan struct{})
+		go func() {
+			gm.ns.NatsServer.WaitForShutdown()
+			close(stopped)
+		}()
+		select {
+		case <-stopped:
+		case <-ctx.Done():
+			log.Printf("⚠️ NATS server did not stop before the shutdown deadline")
+		}
 	}
 
 	gm.cancel()
 	log.Printf("🛑 NATS Game Manager stopped")
-	return nil
+	return flushErr
+}
+
+// Drain stops the manager from accepting player actions ahead of shutdown
+func (gm *NATSGameManager) Drain() {
+	if gm.draining.CompareAndSwap(false, true) {
+		log.Printf("🚧 Draining: no longer accepting player actions")
+	}
+}
+
+// IsDraining reports whether Drain has been called
+func (gm *NATSGameManager) IsDraining() bool {
+	return gm.draining.Load()
 }
 
 func (gm *NATSGameManager) GetNC() *nats.Conn {
@@ -251,6 +397,8 @@ func (gm *NATSGameManager) GetGameState() (*GameState, error) {
 		RoundTimeRemaining: gm.state.RoundTimeRemaining,
 		Countdown:          gm.state.Countdown,
 		Winner:             gm.state.Winner,
+		Paused:             gm.state.Paused,
+		RoundID:            gm.state.RoundID,
 	}
 
 	// Copy teams
@@ -301,6 +449,13 @@ func (gm *NATSGameManager) AddPlayer(playerID string) (*Player, error) {
 	gm.stateMu.Lock()
 	defer gm.stateMu.Unlock()
 
+	if gm.banned[playerID] {
+		return nil, ErrPlayerBanned
+	}
+	if gm.kickedLocked(playerID) {
+		return nil, ErrPlayerKicked
+	}
+
 	// Check if player already exists
 	if p, t := gm.getPlayerLocked(playerID); p != nil {
 		if !p.IsConnected {
@@ -317,18 +472,28 @@ func (gm *NATSGameManager) AddPlayer(playerID string) (*Player, error) {
 		return p, nil
 	}
 
-	// Assign to team with fewest players
+	// During a tournament only agents join, each on its own team
+	agent, ok := gm.tournamentAgentLocked(playerID)
+	if !ok {
+		return nil, ErrTournamentInProgress
+	}
+
+	// Assign agents to their team, everyone else to the team with fewest players
 	var targetTeam *Team
-	minPlayers := -1
-	for _, team := range gm.state.Teams {
-		count := 0
-		team.Players.Range(func(k, v interface{}) bool {
-			count++
-			return true
-		})
-		if minPlayers == -1 || count < minPlayers {
-			minPlayers = count
-			targetTeam = team
+	if agent != nil {
+		targetTeam = gm.state.Teams[agent.TeamID]
+	} else {
+		minPlayers := -1
+		for _, team := range gm.state.Teams {
+			count := 0
+			team.Players.Range(func(k, v interface{}) bool {
+				count++
+				return true
+			})
+			if minPlayers == -1 || count < minPlayers {
+				minPlayers = count
+				targetTeam = team
+			}
 		}
 	}
 
@@ -415,6 +580,10 @@ func (gm *NATSGameManager) UpdatePlayer(playerID string, updates map[string]inte
 }
 
 func (gm *NATSGameManager) PlaceBit(playerID string, x, y int) (bool, error) {
+	if gm.draining.Load() {
+		return false, ErrShuttingDown
+	}
+
 	gm.stateMu.Lock()
 	defer gm.stateMu.Unlock()
 
@@ -427,6 +596,10 @@ func (gm *NATSGameManager) PlaceBit(playerID string, x, y int) (bool, error) {
 		return false, fmt.Errorf("can only place bits during a round")
 	}
 
+	if gm.state.Paused {
+		return false, fmt.Errorf("round is paused")
+	}
+
 	if player.Bits < 1 {
 		return false, fmt.Errorf("not enough bits")
 	}
@@ -465,8 +638,10 @@ func (gm *NATSGameManager) PlaceBit(playerID string, x, y int) (bool, error) {
 	// Save and broadcast
 	gm.saveGameStateToKV()
 	gm.PublishGameEvent("bit_placed", map[string]interface{}{
+		"roundId":  gm.state.RoundID,
 		"playerId": playerID,
 		"teamId":   team.ID,
+		"color":    team.Color,
 		"x":        x,
 		"y":        y,
 		"oldOwner": oldOwnerID,
@@ -512,6 +687,12 @@ func (gm *NATSGameManager) BroadcastTeamUpdate(teamID string) error {
 
 // Event streaming
 func (gm *NATSGameManager) PublishGameEvent(eventType string, data interface{}) error {
+	_, err := gm.publishGameEvent(eventType, data)
+	return err
+}
+
+// publishGameEvent publishes to GAME_EVENTS and returns the stream sequence
+func (gm *NATSGameManager) publishGameEvent(eventType string, data interface{}) (uint64, error) {
 	event := &GameEventMessage{
 		Type:      eventType,
 		Data:      data,
@@ -520,16 +701,16 @@ func (gm *NATSGameManager) PublishGameEvent(eventType string, data interface{})
 
 	eventData, err := json.Marshal(event)
 	if err != nil {
-		return fmt.Errorf("failed to marshal event: %w", err)
+		return 0, fmt.Errorf("failed to marshal event: %w", err)
 	}
 
 	subject := fmt.Sprintf("game.%s", eventType)
-	_, err = gm.js.Publish(gm.ctx, subject, eventData)
+	ack, err := gm.js.Publish(gm.ctx, subject, eventData)
 	if err != nil {
-		return fmt.Errorf("failed to publish event: %w", err)
+		return 0, fmt.Errorf("failed to publish event: %w", err)
 	}
 
-	return nil
+	return ack.Sequence, nil
 }
 
 func (gm *NATSGameManager) SubscribeToGameEvents(handler func(*GameEventMessage)) error {
@@ -558,6 +739,8 @@ func (gm *NATSGameManager) saveGameStateToKV() error {
 		RoundTimeRemaining: gm.state.RoundTimeRemaining,
 		Countdown:          gm.state.Countdown,
 		Winner:             gm.state.Winner,
+		Paused:             gm.state.Paused,
+		RoundID:            gm.state.RoundID,
 		Timestamp:          time.Now().UnixMilli(),
 	}
 
@@ -594,6 +777,8 @@ func (gm *NATSGameManager) loadGameStateFromKV() (*GameState, error) {
 		RoundTimeRemaining: snapshot.RoundTimeRemaining,
 		Countdown:          snapshot.Countdown,
 		Winner:             snapshot.Winner,
+		Paused:             snapshot.Paused,
+		RoundID:            snapshot.RoundID,
 	}
 
 	// Convert regular map back to sync.Map
@@ -626,9 +811,13 @@ func (gm *NATSGameManager) gameLoop() {
 	defer bitsTicker.Stop()
 	defer broadcastTicker.Stop()
 
+	defer close(gm.gameLoopExited)
+
 	dirty := false
 
 	log.Printf("⚡ Starting NATS game loop")
+	gm.lastTick.Store(time.Now().UnixNano())
+	gm.lastBitsTick.Store(time.Now().UnixNano())
 
 	for {
 		select {
@@ -637,13 +826,18 @@ func (gm *NATSGameManager) gameLoop() {
 
 		case <-gameTicker.C:
 			gm.stateMu.Lock()
+			gm.lastTick.Store(time.Now().UnixNano())
+			if gm.state.Paused {
+				gm.stateMu.Unlock()
+				continue
+			}
 			switch gm.state.RoundState {
 			case Waiting:
 				gm.state.Countdown -= time.Second
 				if gm.state.Countdown <= 0 {
 					gm.state.RoundState = InProgress
 					gm.state.RoundTimeRemaining = RoundDuration
-					gm.PublishGameEvent("round_started", nil)
+					gm.startRound()
 					log.Printf("🏁 Round started!")
 				}
 			case InProgress:
@@ -652,9 +846,7 @@ func (gm *NATSGameManager) gameLoop() {
 					gm.state.RoundState = Finished
 					gm.state.Countdown = PostRoundDelay
 					gm.determineWinner()
-					gm.PublishGameEvent("round_finished", map[string]interface{}{
-						"winner": gm.state.Winner,
-					})
+					gm.finishRound()
 					if gm.state.Winner != nil {
 						log.Printf("🏆 Round finished! Winner: %s", gm.state.Winner.ID)
 					}
@@ -674,8 +866,9 @@ func (gm *NATSGameManager) gameLoop() {
 			gm.stateMu.Unlock()
 
 		case <-bitsTicker.C:
+			gm.lastBitsTick.Store(time.Now().UnixNano())
 			gm.stateMu.Lock()
-			if gm.state.RoundState == InProgress {
+			if gm.state.RoundState == InProgress && !gm.state.Paused {
 				gm.regenerateBits()
 				dirty = true
 			}
@@ -0,0 +1,32 @@
+package types
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// newTestManager starts a game manager on a random port with its data in a
+// temporary directory, and shuts it down when the test ends
+func newTestManager(t *testing.T) *NATSGameManager {
+	t.Helper()
+	gm, err := NewNATSGameManager(context.Background(), &NATSConfig{
+		DataDir:  t.TempDir(),
+		Port:     -1,
+		MaxAge:   time.Hour,
+		MaxBytes: 8 * 1024 * 1024,
+		Replicas: 1,
+	})
+	if err != nil {
+		t.Fatalf("NewNATSGameManager: %v", err)
+	}
+	if err := gm.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		gm.Shutdown(ctx)
+	})
+	return gm
+}
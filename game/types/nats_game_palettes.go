@@ -0,0 +1,134 @@
+package types
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// ErrUnknownPalette is returned when selecting a palette that does not exist
+var ErrUnknownPalette = errors.New("unknown palette")
+
+// kvKeyPrefsPrefix prefixes the game_data KV keys holding player preferences
+const kvKeyPrefsPrefix = "prefs."
+
+// TeamIDs lists the teams in the order they are created
+var TeamIDs = []string{"Glitchbyte", "Nullwave", "Overburn", "Voltcrash"}
+
+// Palette IDs
+const (
+	PaletteDefault      = "default"
+	PaletteDeuteranopia = "deuteranopia"
+	PaletteHighContrast = "high-contrast"
+	PalettePatterns     = "patterns"
+)
+
+// Palette maps each team to the color it is drawn in. Patterns adds a
+// distinct texture per team so colors are not the only cue.
+type Palette struct {
+	ID       string            `json:"id"`
+	Colors   map[string]string `json:"colors"`
+	Patterns bool              `json:"patterns,omitempty"`
+}
+
+// Palettes are the selectable team palettes, default first
+var Palettes = []*Palette{
+	{
+		ID: PaletteDefault,
+		Colors: map[string]string{
+			"Glitchbyte": "#d500f9",
+			"Nullwave":   "#00bcd4",
+			"Overburn":   "#e91e63",
+			"Voltcrash":  "#76ff03",
+		},
+	},
+	{
+		// Okabe-Ito colors, distinguishable with red-green color blindness
+		ID: PaletteDeuteranopia,
+		Colors: map[string]string{
+			"Glitchbyte": "#0072b2",
+			"Nullwave":   "#e69f00",
+			"Overburn":   "#cc79a7",
+			"Voltcrash":  "#f0e442",
+		},
+	},
+	{
+		ID: PaletteHighContrast,
+		Colors: map[string]string{
+			"Glitchbyte": "#000000",
+			"Nullwave":   "#ffd600",
+			"Overburn":   "#2962ff",
+			"Voltcrash":  "#d50000",
+		},
+	},
+	{
+		ID: PalettePatterns,
+		Colors: map[string]string{
+			"Glitchbyte": "#d500f9",
+			"Nullwave":   "#00bcd4",
+			"Overburn":   "#e91e63",
+			"Voltcrash":  "#76ff03",
+		},
+		Patterns: true,
+	},
+}
+
+// GetPalette returns the palette with the given ID
+func GetPalette(id string) (*Palette, bool) {
+	for _, p := range Palettes {
+		if p.ID == id {
+			return p, true
+		}
+	}
+	return nil, false
+}
+
+// PlayerPrefs are per-player display settings kept across rounds and restarts
+type PlayerPrefs struct {
+	Palette string `json:"palette,omitempty"`
+}
+
+// GetPlayerPrefs loads a player's preferences, returning defaults if none
+// are stored
+func (gm *NATSGameManager) GetPlayerPrefs(playerID string) (*PlayerPrefs, error) {
+	prefs := &PlayerPrefs{Palette: PaletteDefault}
+
+	entry, err := gm.data.Get(gm.ctx, kvKeyPrefsPrefix+playerID)
+	if errors.Is(err, jetstream.ErrKeyNotFound) {
+		return prefs, nil
+	}
+	if err != nil {
+		return prefs, fmt.Errorf("failed to get preferences: %w", err)
+	}
+	if err := json.Unmarshal(entry.Value(), prefs); err != nil {
+		return prefs, fmt.Errorf("failed to unmarshal preferences: %w", err)
+	}
+	if _, ok := GetPalette(prefs.Palette); !ok {
+		prefs.Palette = PaletteDefault
+	}
+	return prefs, nil
+}
+
+// SetPlayerPalette stores the palette a player sees the game in
+func (gm *NATSGameManager) SetPlayerPalette(playerID, paletteID string) error {
+	if _, ok := GetPalette(paletteID); !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownPalette, paletteID)
+	}
+
+	prefs, err := gm.GetPlayerPrefs(playerID)
+	if err != nil {
+		return err
+	}
+	prefs.Palette = paletteID
+
+	data, err := json.Marshal(prefs)
+	if err != nil {
+		return fmt.Errorf("failed to marshal preferences: %w", err)
+	}
+	if _, err := gm.data.Put(gm.ctx, kvKeyPrefsPrefix+playerID, data); err != nil {
+		return fmt.Errorf("failed to save preferences: %w", err)
+	}
+	return nil
+}
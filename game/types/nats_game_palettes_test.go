@@ -0,0 +1,49 @@
+package types
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestPlayerPalette(t *testing.T) {
+	gm := newTestManager(t)
+
+	tests := []struct {
+		name    string
+		set     string // palette to select, empty to only read
+		stored  string // raw preferences to store first, if any
+		want    string
+		wantErr error
+	}{
+		{name: "default when unset", want: PaletteDefault},
+		{name: "select", set: PaletteDeuteranopia, want: PaletteDeuteranopia},
+		{name: "select again", set: PalettePatterns, want: PalettePatterns},
+		{name: "unknown keeps the previous", set: "sepia", want: PalettePatterns, wantErr: ErrUnknownPalette},
+		{name: "removed palette reads as default", stored: `{"palette":"retired"}`, want: PaletteDefault},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.stored != "" {
+				if _, err := gm.data.Put(gm.ctx, kvKeyPrefsPrefix+"p1", []byte(tt.stored)); err != nil {
+					t.Fatalf("Put: %v", err)
+				}
+			}
+			if tt.set != "" {
+				if err := gm.SetPlayerPalette("p1", tt.set); !errors.Is(err, tt.wantErr) {
+					t.Fatalf("SetPlayerPalette error = %v, want %v", err, tt.wantErr)
+				}
+			}
+			prefs, err := gm.GetPlayerPrefs("p1")
+			if err != nil {
+				t.Fatalf("GetPlayerPrefs: %v", err)
+			}
+			if prefs.Palette != tt.want {
+				t.Errorf("palette = %q, want %q", prefs.Palette, tt.want)
+			}
+		})
+	}
+
+	if prefs, err := gm.GetPlayerPrefs("p2"); err != nil || prefs.Palette != PaletteDefault {
+		t.Errorf("other player palette = %v, %v; want %q", prefs, err, PaletteDefault)
+	}
+}
@@ -0,0 +1,263 @@
+package types
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+var (
+	// ErrRoundNotFound is returned for unknown or expired round IDs
+	ErrRoundNotFound = errors.New("round not found")
+	// ErrRoundEventsExpired is returned when a round is known but its events
+	// have aged out of the GAME_EVENTS stream
+	ErrRoundEventsExpired = errors.New("round events have expired")
+)
+
+// kvKeyRoundPrefix prefixes the game_data KV keys holding round records
+const kvKeyRoundPrefix = "round."
+
+// RoundRecord describes one round. Its placements are not stored here but
+// replayed from the GAME_EVENTS stream between StartSeq and EndSeq.
+type RoundRecord struct {
+	ID        int            `json:"id"`
+	StartedAt time.Time      `json:"startedAt"`
+	EndedAt   *time.Time     `json:"endedAt,omitempty"`
+	StartSeq  uint64         `json:"startSeq"`
+	EndSeq    uint64         `json:"endSeq,omitempty"`
+	Winner    string         `json:"winner,omitempty"`
+	Scores    map[string]int `json:"scores,omitempty"`
+}
+
+// Finished reports whether the round has ended
+func (r *RoundRecord) Finished() bool {
+	return r.EndSeq > 0
+}
+
+// Round event kinds yielded by ReplayRound
+const (
+	RoundEventBitPlaced    = "bit_placed"
+	RoundEventGridReset    = "grid_reset"
+	RoundEventRoundStarted = "round_started"
+)
+
+// RoundEvent is a grid change recorded during a round
+type RoundEvent struct {
+	Kind     string    `json:"kind"`
+	Seq      uint64    `json:"seq"`
+	At       time.Time `json:"at"`
+	RoundID  int       `json:"roundId,omitempty"`
+	PlayerID string    `json:"playerId,omitempty"`
+	TeamID   string    `json:"teamId,omitempty"`
+	Color    string    `json:"color,omitempty"`
+	X        int       `json:"x"`
+	Y        int       `json:"y"`
+	OldOwner string    `json:"oldOwner,omitempty"`
+}
+
+// startRound assigns the next round ID, publishes round_started and records
+// the stream position placements start from. Must be called with stateMu held.
+func (gm *NATSGameManager) startRound() {
+	gm.state.RoundID++
+	record := &RoundRecord{
+		ID:        gm.state.RoundID,
+		StartedAt: time.Now(),
+	}
+
+	seq, err := gm.publishGameEvent("round_started", map[string]interface{}{
+		"roundId": record.ID,
+	})
+	if err != nil {
+		log.Printf("❌ Failed to publish round start: %v", err)
+	}
+	record.StartSeq = seq
+
+	gm.currentRound = record
+	if err := gm.saveRoundRecord(record); err != nil {
+		log.Printf("❌ Failed to save round %d: %v", record.ID, err)
+	}
+	gm.startTournamentRound()
+}
+
+// finishRound publishes round_finished and closes the current round record.
+// determineWinner must already have run. Must be called with stateMu held.
+func (gm *NATSGameManager) finishRound() {
+	gm.finishTournamentRound()
+
+	seq, err := gm.publishGameEvent("round_finished", map[string]interface{}{
+		"roundId": gm.state.RoundID,
+		"winner":  gm.state.Winner,
+	})
+	if err != nil {
+		log.Printf("❌ Failed to publish round finish: %v", err)
+	}
+
+	record := gm.currentRound
+	if record == nil || record.ID != gm.state.RoundID {
+		return
+	}
+
+	now := time.Now()
+	record.EndedAt = &now
+	record.EndSeq = seq
+	record.Scores = make(map[string]int, len(gm.state.Teams))
+	for id, team := range gm.state.Teams {
+		record.Scores[id] = team.Score
+	}
+	if gm.state.Winner != nil {
+		record.Winner = gm.state.Winner.ID
+	}
+
+	gm.currentRound = nil
+	if err := gm.saveRoundRecord(record); err != nil {
+		log.Printf("❌ Failed to save round %d: %v", record.ID, err)
+	}
+}
+
+// GetRound returns the record of a round
+func (gm *NATSGameManager) GetRound(roundID int) (*RoundRecord, error) {
+	gm.stateMu.RLock()
+	if current := gm.currentRound; current != nil && current.ID == roundID {
+		record := *current
+		gm.stateMu.RUnlock()
+		return &record, nil
+	}
+	gm.stateMu.RUnlock()
+
+	return gm.loadRoundRecord(roundID)
+}
+
+func (gm *NATSGameManager) loadRoundRecord(roundID int) (*RoundRecord, error) {
+	entry, err := gm.data.Get(gm.ctx, kvKeyRoundPrefix+strconv.Itoa(roundID))
+	if errors.Is(err, jetstream.ErrKeyNotFound) {
+		return nil, ErrRoundNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load round %d: %w", roundID, err)
+	}
+
+	var record RoundRecord
+	if err := json.Unmarshal(entry.Value(), &record); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal round %d: %w", roundID, err)
+	}
+	return &record, nil
+}
+
+// ReplayRound calls fn for every grid change of a round in order. For a round
+// still in progress it stops at the latest event.
+func (gm *NATSGameManager) ReplayRound(ctx context.Context, roundID int, fn func(*RoundEvent) error) error {
+	record, err := gm.GetRound(roundID)
+	if err != nil {
+		return err
+	}
+	if record.StartSeq == 0 {
+		return fmt.Errorf("round %d has no recorded start", roundID)
+	}
+
+	stream, err := gm.js.Stream(ctx, StreamGameEvents)
+	if err != nil {
+		return fmt.Errorf("failed to get game events stream: %w", err)
+	}
+	info, err := stream.Info(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get game events stream info: %w", err)
+	}
+	if info.State.FirstSeq > record.StartSeq {
+		return fmt.Errorf("%w: round %d", ErrRoundEventsExpired, roundID)
+	}
+	lastSeq := info.State.LastSeq
+	if record.Finished() {
+		lastSeq = record.EndSeq
+	}
+
+	consumer, err := gm.js.OrderedConsumer(ctx, StreamGameEvents, jetstream.OrderedConsumerConfig{
+		FilterSubjects: []string{"game.bit_placed", "game.admin.reset_grid"},
+		DeliverPolicy:  jetstream.DeliverByStartSequencePolicy,
+		OptStartSeq:    record.StartSeq,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create replay consumer: %w", err)
+	}
+
+	for {
+		batch, err := consumer.FetchNoWait(256)
+		if err != nil {
+			return fmt.Errorf("failed to fetch round events: %w", err)
+		}
+
+		received := 0
+		for msg := range batch.Messages() {
+			received++
+			meta, err := msg.Metadata()
+			if err != nil {
+				return fmt.Errorf("failed to read event metadata: %w", err)
+			}
+			if meta.Sequence.Stream > lastSeq {
+				return nil
+			}
+
+			event, err := DecodeRoundEvent(msg.Data(), meta.Sequence.Stream)
+			if err != nil {
+				log.Printf("⚠️ Skipping undecodable event %d: %v", meta.Sequence.Stream, err)
+			} else if err := fn(event); err != nil {
+				return err
+			}
+
+			if meta.Sequence.Stream >= lastSeq || meta.NumPending == 0 {
+				return nil
+			}
+		}
+		if err := batch.Error(); err != nil {
+			return fmt.Errorf("failed to fetch round events: %w", err)
+		}
+		if received == 0 {
+			return nil
+		}
+	}
+}
+
+// DecodeRoundEvent converts a bit_placed, admin.reset_grid or round_started
+// GAME_EVENTS message
+func DecodeRoundEvent(data []byte, seq uint64) (*RoundEvent, error) {
+	var message struct {
+		Type      string          `json:"type"`
+		Data      json.RawMessage `json:"data"`
+		Timestamp int64           `json:"timestamp"`
+	}
+	if err := json.Unmarshal(data, &message); err != nil {
+		return nil, err
+	}
+
+	event := &RoundEvent{
+		Seq: seq,
+		At:  time.UnixMilli(message.Timestamp),
+	}
+	if message.Type == "admin.reset_grid" {
+		event.Kind = RoundEventGridReset
+		return event, nil
+	}
+
+	if err := json.Unmarshal(message.Data, event); err != nil {
+		return nil, err
+	}
+	event.Kind = RoundEventBitPlaced
+	if message.Type == "round_started" {
+		event.Kind = RoundEventRoundStarted
+	}
+	return event, nil
+}
+
+func (gm *NATSGameManager) saveRoundRecord(record *RoundRecord) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	_, err = gm.data.Put(gm.ctx, kvKeyRoundPrefix+strconv.Itoa(record.ID), data)
+	return err
+}
@@ -0,0 +1,103 @@
+package types
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+func TestReplayRound(t *testing.T) {
+	gm := newTestManager(t)
+
+	place := func(x int) uint64 {
+		t.Helper()
+		seq, err := gm.publishGameEvent("bit_placed", map[string]interface{}{"x": x, "y": 0, "teamId": "red"})
+		if err != nil {
+			t.Fatalf("publish: %v", err)
+		}
+		return seq
+	}
+	save := func(record *RoundRecord) {
+		t.Helper()
+		if err := gm.saveRoundRecord(record); err != nil {
+			t.Fatalf("saveRoundRecord: %v", err)
+		}
+	}
+
+	place(0) // before the rounds
+	start := place(1)
+	place(2)
+	end, err := gm.publishGameEvent("admin.reset_grid", nil)
+	if err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+	next := place(3)
+	place(4)
+
+	save(&RoundRecord{ID: 101, StartedAt: time.Now(), StartSeq: start, EndSeq: end})
+	save(&RoundRecord{ID: 102, StartedAt: time.Now(), StartSeq: next})
+	save(&RoundRecord{ID: 103, StartedAt: time.Now()})
+
+	tests := []struct {
+		name    string
+		roundID int
+		want    []string // kinds and x of the replayed events
+		wantErr error
+	}{
+		{"finished round stops at its end", 101, []string{"bit_placed 1", "bit_placed 2", "grid_reset 0"}, nil},
+		{"round in progress runs to the latest event", 102, []string{"bit_placed 3", "bit_placed 4"}, nil},
+		{"unknown round", 999, nil, ErrRoundNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			err := gm.ReplayRound(context.Background(), tt.roundID, func(event *RoundEvent) error {
+				got = append(got, fmt.Sprintf("%s %d", event.Kind, event.X))
+				return nil
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ReplayRound error = %v, want %v", err, tt.wantErr)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("replayed %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("replayed %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+
+	if err := gm.ReplayRound(context.Background(), 103, func(*RoundEvent) error { return nil }); err == nil {
+		t.Error("ReplayRound of a round without a recorded start succeeded")
+	}
+
+	stop := errors.New("stop")
+	calls := 0
+	err = gm.ReplayRound(context.Background(), 101, func(*RoundEvent) error {
+		calls++
+		return stop
+	})
+	if !errors.Is(err, stop) || calls != 1 {
+		t.Errorf("ReplayRound returned %v after %d calls, want the callback error after 1", err, calls)
+	}
+
+	stream, err := gm.js.Stream(context.Background(), StreamGameEvents)
+	if err != nil {
+		t.Fatalf("Stream: %v", err)
+	}
+	if err := stream.Purge(context.Background(), jetstream.WithPurgeSequence(next)); err != nil {
+		t.Fatalf("Purge: %v", err)
+	}
+	if err := gm.ReplayRound(context.Background(), 101, func(*RoundEvent) error { return nil }); !errors.Is(err, ErrRoundEventsExpired) {
+		t.Errorf("ReplayRound of a purged round = %v, want ErrRoundEventsExpired", err)
+	}
+	if err := gm.ReplayRound(context.Background(), 102, func(*RoundEvent) error { return nil }); err != nil {
+		t.Errorf("ReplayRound of a round after the purge: %v", err)
+	}
+}
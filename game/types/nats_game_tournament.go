@@ -0,0 +1,479 @@
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"slices"
+	"sort"
+	"time"
+)
+
+// Agent tournaments
+//
+// While a tournament is active only registered agents may join, each on the
+// team it was registered to, and every finished round is recorded as a match
+// that updates the agents' ratings. Tournament state is persisted to the
+// game_data KV bucket and changes publish "tournament.<action>" events.
+
+// kvKeyTournament is the game_data KV key holding the tournament state
+const kvKeyTournament = "tournament"
+
+// Rating parameters: agents start at InitialRating and move by up to
+// RatingK points per match
+const (
+	InitialRating   = 1500.0
+	RatingK         = 32.0
+	maxAgentName    = 40
+	maxMatchHistory = 100
+)
+
+// TournamentAgent is a registered agent and its standing
+type TournamentAgent struct {
+	PlayerID     string    `json:"playerId"`
+	Name         string    `json:"name"`
+	TeamID       string    `json:"teamId"`
+	Rating       float64   `json:"rating"`
+	Matches      int       `json:"matches"`
+	Wins         int       `json:"wins"`
+	RegisteredAt time.Time `json:"registeredAt"`
+}
+
+// TournamentMatch is the result of one tournament round
+type TournamentMatch struct {
+	RoundID       int                `json:"roundId"`
+	EndedAt       time.Time          `json:"endedAt"`
+	Winner        string             `json:"winner,omitempty"`
+	Scores        map[string]int     `json:"scores"`
+	Teams         map[string]string  `json:"teams"`         // agent player ID -> team ID
+	RatingChanges map[string]float64 `json:"ratingChanges"` // agent player ID -> delta
+}
+
+// Tournament is the tournament schedule, its agents and recent matches.
+// Rounds is the number of rounds to play, 0 meaning until stopped;
+// CurrentRound is the ID of the tournament round in progress, if any.
+type Tournament struct {
+	Active       bool               `json:"active"`
+	Rounds       int                `json:"rounds"`
+	RoundsPlayed int                `json:"roundsPlayed"`
+	CurrentRound int                `json:"currentRound,omitempty"`
+	Agents       []*TournamentAgent `json:"agents"` // highest rating first
+	Matches      []*TournamentMatch `json:"matches"`
+}
+
+// tournamentState is the persisted form of the tournament, guarded by stateMu
+type tournamentState struct {
+	Active       bool                        `json:"active"`
+	Rounds       int                         `json:"rounds"`
+	RoundsPlayed int                         `json:"roundsPlayed"`
+	CurrentRound int                         `json:"currentRound,omitempty"`
+	Agents       map[string]*TournamentAgent `json:"agents"`
+	Matches      []*TournamentMatch          `json:"matches"`
+}
+
+func newTournamentState() *tournamentState {
+	return &tournamentState{Agents: make(map[string]*TournamentAgent)}
+}
+
+// RegisterAgent registers playerID as a tournament agent playing for teamID,
+// or for the team with the fewest agents when teamID is empty. Registering an
+// agent again renames or moves it and keeps its rating.
+func (gm *NATSGameManager) RegisterAgent(playerID, name, teamID string) (*TournamentAgent, error) {
+	if playerID == "" {
+		return nil, fmt.Errorf("player ID is required")
+	}
+	if teamID != "" && !slices.Contains(TeamIDs, teamID) {
+		return nil, fmt.Errorf("unknown team %q", teamID)
+	}
+	if name == "" {
+		name = playerID
+	}
+	if len(name) > maxAgentName {
+		return nil, fmt.Errorf("agent name must be at most %d characters", maxAgentName)
+	}
+
+	gm.stateMu.Lock()
+	defer gm.stateMu.Unlock()
+
+	if gm.banned[playerID] {
+		return nil, ErrPlayerBanned
+	}
+
+	t := gm.tournament
+	if teamID == "" {
+		teamID = gm.smallestAgentTeamLocked()
+	}
+
+	agent, ok := t.Agents[playerID]
+	if !ok {
+		agent = &TournamentAgent{
+			PlayerID:     playerID,
+			Rating:       InitialRating,
+			RegisteredAt: time.Now(),
+		}
+		t.Agents[playerID] = agent
+	}
+	agent.Name = name
+	agent.TeamID = teamID
+
+	if t.Active {
+		gm.seatAgentLocked(agent)
+	}
+	if err := gm.saveTournamentToKV(); err != nil {
+		return nil, fmt.Errorf("failed to save tournament: %w", err)
+	}
+	gm.publishTournamentEvent("register", map[string]interface{}{
+		"playerId": playerID,
+		"name":     name,
+		"teamId":   teamID,
+	})
+
+	log.Printf("🤖 Registered tournament agent %s (%s) on team %s", name, playerID, teamID)
+	copied := *agent
+	return &copied, nil
+}
+
+// RemoveAgent unregisters an agent. During a tournament the agent also leaves
+// its team.
+func (gm *NATSGameManager) RemoveAgent(playerID string) error {
+	gm.stateMu.Lock()
+	defer gm.stateMu.Unlock()
+
+	t := gm.tournament
+	if _, ok := t.Agents[playerID]; !ok {
+		return fmt.Errorf("%w: no agent %s", ErrPlayerNotFound, playerID)
+	}
+	delete(t.Agents, playerID)
+
+	if t.Active {
+		if p, team := gm.getPlayerLocked(playerID); p != nil {
+			team.Players.Delete(playerID)
+			gm.updateTeamPlayerCounts()
+			gm.saveGameStateToKV()
+		}
+	}
+	if err := gm.saveTournamentToKV(); err != nil {
+		return fmt.Errorf("failed to save tournament: %w", err)
+	}
+	gm.publishTournamentEvent("remove", map[string]interface{}{
+		"playerId": playerID,
+	})
+
+	log.Printf("🤖 Removed tournament agent %s", playerID)
+	return nil
+}
+
+// StartTournament schedules rounds tournament rounds (0 plays until stopped).
+// Players that are not registered agents leave the game, agents move to their
+// teams and the countdown to the first tournament round starts.
+func (gm *NATSGameManager) StartTournament(rounds int) error {
+	if rounds < 0 {
+		return fmt.Errorf("rounds must not be negative")
+	}
+
+	gm.stateMu.Lock()
+	defer gm.stateMu.Unlock()
+
+	t := gm.tournament
+	if t.Active {
+		return fmt.Errorf("a tournament is already running")
+	}
+	if len(t.Agents) < 2 {
+		return fmt.Errorf("a tournament needs at least 2 registered agents")
+	}
+	if gm.state.RoundState == InProgress {
+		return fmt.Errorf("a round is in progress; end it before starting a tournament")
+	}
+
+	removed := 0
+	for _, team := range gm.state.Teams {
+		team.Players.Range(func(key, value interface{}) bool {
+			if _, ok := t.Agents[key.(string)]; !ok {
+				team.Players.Delete(key)
+				removed++
+			}
+			return true
+		})
+	}
+	for _, agent := range t.Agents {
+		gm.seatAgentLocked(agent)
+	}
+
+	t.Active = true
+	t.Rounds = rounds
+	t.RoundsPlayed = 0
+	t.CurrentRound = 0
+
+	gm.resetGame()
+	gm.state.RoundState = Waiting
+	gm.state.Countdown = PreRoundCountdown
+	gm.state.Paused = false
+	gm.updateTeamPlayerCounts()
+
+	gm.saveGameStateToKV()
+	if err := gm.saveTournamentToKV(); err != nil {
+		return fmt.Errorf("failed to save tournament: %w", err)
+	}
+	gm.publishTournamentEvent("start", map[string]interface{}{
+		"rounds":  rounds,
+		"agents":  len(t.Agents),
+		"removed": removed,
+	})
+
+	log.Printf("🏟️ Tournament started with %d agents for %d rounds (%d players removed)", len(t.Agents), rounds, removed)
+	return nil
+}
+
+// StopTournament ends the tournament and lets everyone join again. A round
+// in progress keeps running but is not recorded as a match.
+func (gm *NATSGameManager) StopTournament() error {
+	gm.stateMu.Lock()
+	defer gm.stateMu.Unlock()
+
+	if !gm.tournament.Active {
+		return fmt.Errorf("no tournament is running")
+	}
+	gm.endTournamentLocked()
+	if err := gm.saveTournamentToKV(); err != nil {
+		return fmt.Errorf("failed to save tournament: %w", err)
+	}
+	return nil
+}
+
+// GetTournament returns a copy of the tournament with agents ranked by rating
+func (gm *NATSGameManager) GetTournament() (*Tournament, error) {
+	gm.stateMu.RLock()
+	defer gm.stateMu.RUnlock()
+
+	t := gm.tournament
+	result := &Tournament{
+		Active:       t.Active,
+		Rounds:       t.Rounds,
+		RoundsPlayed: t.RoundsPlayed,
+		CurrentRound: t.CurrentRound,
+		Agents:       make([]*TournamentAgent, 0, len(t.Agents)),
+		Matches:      slices.Clone(t.Matches),
+	}
+	for _, agent := range t.Agents {
+		copied := *agent
+		result.Agents = append(result.Agents, &copied)
+	}
+	sort.Slice(result.Agents, func(i, j int) bool {
+		if result.Agents[i].Rating != result.Agents[j].Rating {
+			return result.Agents[i].Rating > result.Agents[j].Rating
+		}
+		return result.Agents[i].PlayerID < result.Agents[j].PlayerID
+	})
+	return result, nil
+}
+
+// tournamentAgentLocked returns the agent a player joins as. ok is false when
+// a tournament is running and the player is not a registered agent. Must be
+// called with stateMu held.
+func (gm *NATSGameManager) tournamentAgentLocked(playerID string) (agent *TournamentAgent, ok bool) {
+	if !gm.tournament.Active {
+		return nil, true
+	}
+	agent, ok = gm.tournament.Agents[playerID]
+	return agent, ok
+}
+
+// seatAgentLocked moves an agent that is already playing onto its team. Must
+// be called with stateMu held.
+func (gm *NATSGameManager) seatAgentLocked(agent *TournamentAgent) {
+	p, team := gm.getPlayerLocked(agent.PlayerID)
+	if p == nil || team.ID == agent.TeamID {
+		return
+	}
+	target, ok := gm.state.Teams[agent.TeamID]
+	if !ok {
+		return
+	}
+	team.Players.Delete(agent.PlayerID)
+	p.TeamID = target.ID
+	p.Color = target.Color
+	target.Players.Store(agent.PlayerID, p)
+	gm.updateTeamPlayerCounts()
+	gm.saveGameStateToKV()
+	log.Printf("🤖 Moved agent %s from team %s to team %s", agent.PlayerID, team.ID, target.ID)
+}
+
+// smallestAgentTeamLocked returns the team with the fewest registered agents
+func (gm *NATSGameManager) smallestAgentTeamLocked() string {
+	counts := make(map[string]int, len(TeamIDs))
+	for _, agent := range gm.tournament.Agents {
+		counts[agent.TeamID]++
+	}
+	best := TeamIDs[0]
+	for _, id := range TeamIDs[1:] {
+		if counts[id] < counts[best] {
+			best = id
+		}
+	}
+	return best
+}
+
+// startTournamentRound marks the round that just started as a tournament
+// round. Must be called with stateMu held.
+func (gm *NATSGameManager) startTournamentRound() {
+	if !gm.tournament.Active {
+		return
+	}
+	gm.tournament.CurrentRound = gm.state.RoundID
+	if err := gm.saveTournamentToKV(); err != nil {
+		log.Printf("❌ Failed to save tournament: %v", err)
+	}
+}
+
+// finishTournamentRound records the finished round as a match and rates the
+// agents that played it. determineWinner must already have run. Must be
+// called with stateMu held.
+func (gm *NATSGameManager) finishTournamentRound() {
+	t := gm.tournament
+	if !t.Active || t.CurrentRound != gm.state.RoundID {
+		return
+	}
+
+	match := &TournamentMatch{
+		RoundID:       gm.state.RoundID,
+		EndedAt:       time.Now(),
+		Scores:        make(map[string]int, len(gm.state.Teams)),
+		Teams:         make(map[string]string),
+		RatingChanges: make(map[string]float64),
+	}
+	if gm.state.Winner != nil {
+		match.Winner = gm.state.Winner.ID
+	}
+
+	// Agents on the board at the end of the round played it
+	lineups := make(map[string][]*TournamentAgent)
+	for id, team := range gm.state.Teams {
+		match.Scores[id] = team.Score
+		team.Players.Range(func(key, value interface{}) bool {
+			if agent, ok := t.Agents[key.(string)]; ok {
+				lineups[id] = append(lineups[id], agent)
+				match.Teams[agent.PlayerID] = id
+			}
+			return true
+		})
+	}
+
+	for teamID, delta := range teamRatingChanges(lineups, match.Scores) {
+		for _, agent := range lineups[teamID] {
+			agent.Rating += delta
+			match.RatingChanges[agent.PlayerID] = delta
+		}
+	}
+	for teamID, agents := range lineups {
+		for _, agent := range agents {
+			agent.Matches++
+			if teamID == match.Winner {
+				agent.Wins++
+			}
+		}
+	}
+
+	t.Matches = append(t.Matches, match)
+	if len(t.Matches) > maxMatchHistory {
+		t.Matches = t.Matches[len(t.Matches)-maxMatchHistory:]
+	}
+	t.RoundsPlayed++
+	t.CurrentRound = 0
+	gm.publishTournamentEvent("match", map[string]interface{}{
+		"roundId":       match.RoundID,
+		"winner":        match.Winner,
+		"ratingChanges": match.RatingChanges,
+	})
+	log.Printf("🏟️ Tournament round %d recorded (%d/%d)", match.RoundID, t.RoundsPlayed, t.Rounds)
+
+	if t.Rounds > 0 && t.RoundsPlayed >= t.Rounds {
+		gm.endTournamentLocked()
+	}
+	if err := gm.saveTournamentToKV(); err != nil {
+		log.Printf("❌ Failed to save tournament: %v", err)
+	}
+}
+
+// teamRatingChanges rates each team with agents against every other such team
+// by the Elo formula on the teams' mean agent ratings. Returns the change for
+// each agent of a team; nothing when fewer than two teams had agents.
+func teamRatingChanges(lineups map[string][]*TournamentAgent, scores map[string]int) map[string]float64 {
+	ratings := make(map[string]float64, len(lineups))
+	for teamID, agents := range lineups {
+		if len(agents) == 0 {
+			continue
+		}
+		sum := 0.0
+		for _, agent := range agents {
+			sum += agent.Rating
+		}
+		ratings[teamID] = sum / float64(len(agents))
+	}
+	if len(ratings) < 2 {
+		return nil
+	}
+
+	changes := make(map[string]float64, len(ratings))
+	for a, ratingA := range ratings {
+		total := 0.0
+		for b, ratingB := range ratings {
+			if a == b {
+				continue
+			}
+			actual := 0.5
+			if scores[a] > scores[b] {
+				actual = 1
+			} else if scores[a] < scores[b] {
+				actual = 0
+			}
+			expected := 1 / (1 + math.Pow(10, (ratingB-ratingA)/400))
+			total += actual - expected
+		}
+		changes[a] = math.Round(RatingK*total/float64(len(ratings)-1)*100) / 100
+	}
+	return changes
+}
+
+// endTournamentLocked stops the tournament. Must be called with stateMu held.
+func (gm *NATSGameManager) endTournamentLocked() {
+	t := gm.tournament
+	t.Active = false
+	t.CurrentRound = 0
+	gm.publishTournamentEvent("end", map[string]interface{}{
+		"roundsPlayed": t.RoundsPlayed,
+	})
+	log.Printf("🏟️ Tournament ended after %d rounds", t.RoundsPlayed)
+}
+
+// publishTournamentEvent records a tournament change on game.tournament.<action>
+func (gm *NATSGameManager) publishTournamentEvent(action string, data map[string]interface{}) {
+	if err := gm.PublishGameEvent("tournament."+action, data); err != nil {
+		log.Printf("❌ Failed to publish tournament %s event: %v", action, err)
+	}
+}
+
+func (gm *NATSGameManager) saveTournamentToKV() error {
+	data, err := json.Marshal(gm.tournament)
+	if err != nil {
+		return err
+	}
+	_, err = gm.data.Put(gm.ctx, kvKeyTournament, data)
+	return err
+}
+
+func (gm *NATSGameManager) loadTournamentFromKV() (*tournamentState, error) {
+	entry, err := gm.data.Get(gm.ctx, kvKeyTournament)
+	if err != nil {
+		return nil, err
+	}
+
+	t := newTournamentState()
+	if err := json.Unmarshal(entry.Value(), t); err != nil {
+		return nil, err
+	}
+	if t.Agents == nil {
+		t.Agents = make(map[string]*TournamentAgent)
+	}
+	return t, nil
+}
@@ -0,0 +1,81 @@
+package types
+
+import (
+	"maps"
+	"testing"
+)
+
+func TestTeamRatingChanges(t *testing.T) {
+	agents := func(ratings ...float64) []*TournamentAgent {
+		lineup := make([]*TournamentAgent, len(ratings))
+		for i, rating := range ratings {
+			lineup[i] = &TournamentAgent{Rating: rating}
+		}
+		return lineup
+	}
+
+	tests := []struct {
+		name    string
+		lineups map[string][]*TournamentAgent
+		scores  map[string]int
+		want    map[string]float64
+	}{
+		{
+			name:    "equal ratings, win",
+			lineups: map[string][]*TournamentAgent{"red": agents(1500), "blue": agents(1500)},
+			scores:  map[string]int{"red": 10, "blue": 5},
+			want:    map[string]float64{"red": 16, "blue": -16},
+		},
+		{
+			name:    "equal ratings, draw",
+			lineups: map[string][]*TournamentAgent{"red": agents(1500), "blue": agents(1500)},
+			scores:  map[string]int{"red": 7, "blue": 7},
+			want:    map[string]float64{"red": 0, "blue": 0},
+		},
+		{
+			name:    "favourite wins",
+			lineups: map[string][]*TournamentAgent{"red": agents(1600), "blue": agents(1400)},
+			scores:  map[string]int{"red": 10, "blue": 5},
+			want:    map[string]float64{"red": 7.69, "blue": -7.69},
+		},
+		{
+			name:    "underdog wins",
+			lineups: map[string][]*TournamentAgent{"red": agents(1600), "blue": agents(1400)},
+			scores:  map[string]int{"red": 5, "blue": 10},
+			want:    map[string]float64{"red": -24.31, "blue": 24.31},
+		},
+		{
+			name:    "team rating is the agent mean",
+			lineups: map[string][]*TournamentAgent{"red": agents(1700, 1500), "blue": agents(1400, 1800)},
+			scores:  map[string]int{"red": 10, "blue": 5},
+			want:    map[string]float64{"red": 16, "blue": -16},
+		},
+		{
+			name: "three teams, averaged over opponents",
+			lineups: map[string][]*TournamentAgent{
+				"red": agents(1500), "blue": agents(1500), "green": agents(1500),
+			},
+			scores: map[string]int{"red": 3, "blue": 2, "green": 1},
+			want:   map[string]float64{"red": 16, "blue": 0, "green": -16},
+		},
+		{
+			name:    "teams without agents are not rated",
+			lineups: map[string][]*TournamentAgent{"red": agents(1500), "blue": agents(1500), "green": nil},
+			scores:  map[string]int{"red": 1, "blue": 0, "green": 99},
+			want:    map[string]float64{"red": 16, "blue": -16},
+		},
+		{
+			name:    "single team",
+			lineups: map[string][]*TournamentAgent{"red": agents(1500)},
+			scores:  map[string]int{"red": 10},
+			want:    nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := teamRatingChanges(tt.lineups, tt.scores); !maps.Equal(got, tt.want) {
+				t.Errorf("teamRatingChanges = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
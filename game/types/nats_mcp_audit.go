@@ -0,0 +1,132 @@
+package types
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// MCP call outcomes
+const (
+	MCPOutcomeOK       = "ok"
+	MCPOutcomeError    = "error"
+	MCPOutcomeRejected = "rejected" // refused by a quota before running
+)
+
+// MCPCallRecord is one MCP tool invocation in the MCP_AUDIT stream
+type MCPCallRecord struct {
+	At         time.Time       `json:"at"`
+	SessionID  string          `json:"sessionId"`
+	PlayerID   string          `json:"playerId,omitempty"`
+	Tool       string          `json:"tool"`
+	Arguments  json.RawMessage `json:"arguments,omitempty"`
+	Outcome    string          `json:"outcome"`
+	Error      string          `json:"error,omitempty"`
+	LatencyMs  float64         `json:"latencyMs"`
+	Placements int             `json:"placements,omitempty"` // bits the call tried to place
+}
+
+// MCPUsage sums up the calls of one player
+type MCPUsage struct {
+	PlayerID     string         `json:"playerId"`
+	Calls        int            `json:"calls"`
+	Errors       int            `json:"errors"`
+	Rejected     int            `json:"rejected"`
+	Placements   int            `json:"placements"` // attempted by calls that passed the quotas
+	AvgLatencyMs float64        `json:"avgLatencyMs"`
+	LastCall     time.Time      `json:"lastCall"`
+	Tools        map[string]int `json:"tools"`
+}
+
+// RecordMCPCall appends a tool invocation to the MCP_AUDIT stream
+func (gm *NATSGameManager) RecordMCPCall(record *MCPCallRecord) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to marshal MCP call: %w", err)
+	}
+	if _, err := gm.js.Publish(gm.ctx, "mcp.audit."+record.Tool, data); err != nil {
+		return fmt.Errorf("failed to record MCP call: %w", err)
+	}
+	return nil
+}
+
+// MCPUsage reads the audit stream from since and returns per-player totals,
+// busiest first. Calls made before a session was bound to a player are
+// grouped under an empty player ID.
+func (gm *NATSGameManager) MCPUsage(ctx context.Context, since time.Time) ([]*MCPUsage, error) {
+	consumer, err := gm.js.OrderedConsumer(ctx, StreamMCPAudit, jetstream.OrderedConsumerConfig{
+		DeliverPolicy: jetstream.DeliverByStartTimePolicy,
+		OptStartTime:  &since,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create MCP audit consumer: %w", err)
+	}
+
+	usage := make(map[string]*MCPUsage)
+	latency := make(map[string]float64)
+	for done := false; !done; {
+		batch, err := consumer.FetchNoWait(256)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch MCP audit records: %w", err)
+		}
+
+		received := 0
+		for msg := range batch.Messages() {
+			received++
+			var record MCPCallRecord
+			if err := json.Unmarshal(msg.Data(), &record); err != nil {
+				log.Printf("⚠️ Skipping undecodable MCP audit record: %v", err)
+				continue
+			}
+
+			u, ok := usage[record.PlayerID]
+			if !ok {
+				u = &MCPUsage{PlayerID: record.PlayerID, Tools: make(map[string]int)}
+				usage[record.PlayerID] = u
+			}
+			u.Calls++
+			u.Tools[record.Tool]++
+			switch record.Outcome {
+			case MCPOutcomeError:
+				u.Errors++
+				u.Placements += record.Placements
+			case MCPOutcomeRejected:
+				u.Rejected++
+			default:
+				u.Placements += record.Placements
+			}
+			if record.At.After(u.LastCall) {
+				u.LastCall = record.At
+			}
+			latency[record.PlayerID] += record.LatencyMs
+
+			if meta, err := msg.Metadata(); err == nil && meta.NumPending == 0 {
+				done = true
+			}
+		}
+		if err := batch.Error(); err != nil {
+			return nil, fmt.Errorf("failed to fetch MCP audit records: %w", err)
+		}
+		if received == 0 {
+			done = true
+		}
+	}
+
+	result := make([]*MCPUsage, 0, len(usage))
+	for playerID, u := range usage {
+		u.AvgLatencyMs = latency[playerID] / float64(u.Calls)
+		result = append(result, u)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Calls != result[j].Calls {
+			return result[i].Calls > result[j].Calls
+		}
+		return result[i].PlayerID < result[j].PlayerID
+	})
+	return result, nil
+}